@@ -0,0 +1,114 @@
+package aferomock
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+// Expect is the expected changes of AssertChanges. The paths are relative to the root of the file system, in any
+// order, so "data/a.txt" and "/data/a.txt" are the same path, including for the relative paths of an afero.MemMapFs.
+type Expect struct {
+	// Created are the paths of the files and the directories that are created.
+	Created []string
+	// Modified are the paths whose type, permissions, content or link target are changed. A directory is only modified
+	// when its type or permissions change, not when its entries do.
+	Modified []string
+	// Deleted are the paths of the files and the directories that are removed.
+	Deleted []string
+}
+
+// AssertChanges takes a snapshot of the file system, runs the function, and asserts that the file system is changed as
+// expected, see Diff. Every change that is not expected, and every expected change that did not happen, is reported.
+// It works with any afero.Fs, the whole file system is compared, see TakeSnapshot.
+//
+//	aferomock.AssertChanges(t, fs, func() {
+//		require.NoError(t, migrate(fs))
+//	}, aferomock.Expect{
+//		Created:  []string{"/config/v2.yaml"},
+//		Modified: []string{"/config/app.yaml"},
+//		Deleted:  []string{"/config/v1.yaml"},
+//	})
+func AssertChanges(tb testing.TB, fs afero.Fs, fn func(), expect Expect) bool {
+	tb.Helper()
+
+	snap, err := TakeSnapshot(fs)
+	if err != nil {
+		tb.Errorf("aferomock: could not take a snapshot of the file system: %s", err)
+
+		return false
+	}
+
+	before := snap.Clone()
+
+	fn()
+
+	changes, err := Diff(before, fs)
+	if err != nil {
+		tb.Errorf("aferomock: could not compare the file system: %s", err)
+
+		return false
+	}
+
+	var sb strings.Builder
+
+	writeChangesReport(&sb, "Created", expect.Created, changes.Added)
+	writeChangesReport(&sb, "Modified", expect.Modified, changes.Modified)
+	writeChangesReport(&sb, "Deleted", expect.Deleted, changes.Removed)
+
+	if sb.Len() == 0 {
+		return true
+	}
+
+	tb.Errorf("aferomock: unexpected changes of the file system:\n%s", sb.String())
+
+	return false
+}
+
+// writeChangesReport writes the unexpected and the missing paths of a kind of change.
+func writeChangesReport(sb *strings.Builder, kind string, expected, actual []string) {
+	want := make(map[string]bool, len(expected))
+
+	for _, p := range expected {
+		want[rootedPath(p)] = true
+	}
+
+	var unexpected, missing []string
+
+	for _, p := range actual {
+		if want[p] {
+			delete(want, p)
+		} else {
+			unexpected = append(unexpected, p)
+		}
+	}
+
+	for p := range want {
+		missing = append(missing, p)
+	}
+
+	if len(unexpected) == 0 && len(missing) == 0 {
+		return
+	}
+
+	sort.Strings(missing)
+
+	_, _ = fmt.Fprintf(sb, "\t%s:\n", kind)
+
+	for _, p := range unexpected {
+		_, _ = fmt.Fprintf(sb, "\t\tunexpected: %q\n", p)
+	}
+
+	for _, p := range missing {
+		_, _ = fmt.Fprintf(sb, "\t\tmissing:    %q\n", p)
+	}
+}
+
+// rootedPath returns the clean form of the path, relative to the root like the paths of Diff.
+func rootedPath(name string) string {
+	return filepath.Join(string(filepath.Separator), name)
+}
@@ -0,0 +1,174 @@
+package aferomock_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestAssertChanges(t *testing.T) {
+	t.Parallel()
+
+	migrate := func(t *testing.T, fs afero.Fs) func() {
+		t.Helper()
+
+		return func() {
+			require.NoError(t, afero.WriteFile(fs, "/config/v2.yaml", []byte("version: 2"), 0o644))
+			require.NoError(t, afero.WriteFile(fs, "/config/app.yaml", []byte("v2.yaml"), 0o644))
+			require.NoError(t, fs.Remove("/config/v1.yaml"))
+		}
+	}
+
+	testCases := []struct {
+		scenario       string
+		fs             func() afero.Fs
+		expect         aferomock.Expect
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario: "expected",
+			fs:       func() afero.Fs { return aferomock.NewInodeFs() },
+			expect: aferomock.Expect{
+				Created:  []string{"config/v2.yaml"},
+				Modified: []string{"/config/app.yaml"},
+				Deleted:  []string{"/config/./v1.yaml"},
+			},
+			expectedResult: true,
+		},
+		{
+			scenario: "expected with a wrapped fs",
+			fs: func() afero.Fs {
+				return aferomock.OverrideFs(afero.NewMemMapFs(), aferomock.FsCallbacks{})
+			},
+			expect: aferomock.Expect{
+				Created:  []string{"/config/v2.yaml"},
+				Modified: []string{"/config/app.yaml"},
+				Deleted:  []string{"/config/v1.yaml"},
+			},
+			expectedResult: true,
+		},
+		{
+			scenario: "unexpected",
+			fs:       func() afero.Fs { return aferomock.NewInodeFs() },
+			expect: aferomock.Expect{
+				Created: []string{"/config/v3.yaml", "/config/v2.yaml"},
+				Deleted: []string{"/config/v1.yaml"},
+			},
+			expectedError: "aferomock: unexpected changes of the file system:\n" +
+				"\tCreated:\n" +
+				"\t\tmissing:    \"/config/v3.yaml\"\n" +
+				"\tModified:\n" +
+				"\t\tunexpected: \"/config/app.yaml\"\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			fs := tc.fs()
+
+			require.NoError(t, fs.MkdirAll("/config", os.ModePerm))
+			require.NoError(t, afero.WriteFile(fs, "/config/v1.yaml", []byte("version: 1"), 0o644))
+			require.NoError(t, afero.WriteFile(fs, "/config/app.yaml", []byte("v1.yaml"), 0o644))
+
+			ft := newFakeT(t)
+
+			result := aferomock.AssertChanges(ft, fs, migrate(t, fs), tc.expect)
+
+			assert.Equal(t, tc.expectedResult, result)
+
+			if tc.expectedError == "" {
+				assert.Empty(t, ft.Errors())
+			} else {
+				assert.Equal(t, []string{tc.expectedError}, ft.Errors())
+			}
+		})
+	}
+}
+
+func TestAssertChanges_NoChanges(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+
+	require.NoError(t, afero.WriteFile(fs, "/a.txt", []byte("hello"), 0o644))
+
+	ft := newFakeT(t)
+	called := false
+
+	result := aferomock.AssertChanges(ft, fs, func() {
+		called = true
+
+		_, err := afero.ReadFile(fs, "/a.txt")
+		require.NoError(t, err)
+	}, aferomock.Expect{})
+
+	assert.True(t, result)
+	assert.True(t, called)
+	assert.Empty(t, ft.Errors())
+}
+
+func TestAssertChanges_RelativePaths(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+
+	require.NoError(t, afero.WriteFile(fs, "data/x.txt", []byte("x"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "data/y.txt", []byte("y"), 0o644))
+
+	ft := newFakeT(t)
+
+	result := aferomock.AssertChanges(ft, fs, func() {
+		require.NoError(t, afero.WriteFile(fs, "data/x.txt", []byte("changed"), 0o644))
+		require.NoError(t, afero.WriteFile(fs, "data/z.txt", nil, 0o644))
+		require.NoError(t, fs.Remove("data/y.txt"))
+	}, aferomock.Expect{
+		Created:  []string{"data/z.txt"},
+		Modified: []string{"data/x.txt"},
+		Deleted:  []string{"data/y.txt"},
+	})
+
+	assert.True(t, result)
+	assert.Empty(t, ft.Errors())
+}
+
+func TestAssertChanges_Error(t *testing.T) {
+	t.Parallel()
+
+	fail := false
+
+	fs := aferomock.OverrideFs(afero.NewMemMapFs(), aferomock.FsCallbacks{})
+	fs.StatFunc = func(name string) (os.FileInfo, error) {
+		if fail {
+			return nil, errors.New("stat error")
+		}
+
+		return afero.NewMemMapFs().Stat(name)
+	}
+
+	ft := newFakeT(t)
+
+	result := aferomock.AssertChanges(ft, fs, func() {
+		fail = true
+	}, aferomock.Expect{})
+
+	assert.False(t, result)
+	assert.Equal(t, []string{"aferomock: could not compare the file system: stat error"}, ft.Errors())
+
+	ft = newFakeT(t)
+
+	result = aferomock.AssertChanges(ft, fs, func() {
+		t.Error("the function is not expected to be called")
+	}, aferomock.Expect{})
+
+	assert.False(t, result)
+	assert.Equal(t, []string{"aferomock: could not take a snapshot of the file system: stat error"}, ft.Errors())
+}
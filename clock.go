@@ -0,0 +1,333 @@
+package aferomock
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Clock provides the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+var _ Clock = (*FakeClock)(nil)
+
+// FakeClock is a Clock that only changes when it is told to.
+type FakeClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewFakeClock creates a new FakeClock set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.now
+}
+
+// Advance moves the clock forward by the given duration.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// Set sets the clock to the given time.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
+
+// ClockFs wraps an afero.Fs and stamps the modification times with the given clock. Creating, writing and truncating
+// a file sets its modification time, and the modification time of its parent directory when an entry is added or
+// removed. Chtimes sets the modification time to the given value.
+//
+// The FileInfo returned by Stat and Readdir reports the stamped modification time. The files that are not modified
+// through ClockFs report the modification time of the wrapped afero.Fs.
+func ClockFs(fs afero.Fs, clock Clock) FsCallbacks { //nolint: funlen
+	c := &clockStamps{
+		clock:  clock,
+		mtimes: make(map[string]time.Time),
+	}
+
+	return OverrideFs(fs, FsCallbacks{
+		ChtimesFunc: func(name string, atime, mtime time.Time) error {
+			if err := fs.Chtimes(name, atime, mtime); err != nil {
+				return err
+			}
+
+			c.set(name, mtime)
+
+			return nil
+		},
+		CreateFunc: func(name string) (afero.File, error) {
+			_, statErr := fs.Stat(name)
+
+			f, err := fs.Create(name)
+			if err != nil {
+				return nil, err
+			}
+
+			c.touch(name, statErr != nil)
+
+			return c.file(name, f), nil
+		},
+		MkdirFunc: func(name string, perm os.FileMode) error {
+			if err := fs.Mkdir(name, perm); err != nil {
+				return err
+			}
+
+			c.touch(name, true)
+
+			return nil
+		},
+		MkdirAllFunc: func(path string, perm os.FileMode) error {
+			var created []string
+
+			for p := filepath.Clean(path); ; p = filepath.Dir(p) {
+				if _, err := fs.Stat(p); err == nil {
+					break
+				}
+
+				created = append(created, p)
+
+				if filepath.Dir(p) == p {
+					break
+				}
+			}
+
+			if err := fs.MkdirAll(path, perm); err != nil {
+				return err
+			}
+
+			for _, p := range created {
+				c.touch(p, true)
+			}
+
+			return nil
+		},
+		OpenFunc: func(name string) (afero.File, error) {
+			f, err := fs.Open(name)
+			if err != nil {
+				return nil, err
+			}
+
+			return c.file(name, f), nil
+		},
+		OpenFileFunc: func(name string, flag int, perm os.FileMode) (afero.File, error) {
+			_, statErr := fs.Stat(name)
+
+			f, err := fs.OpenFile(name, flag, perm)
+			if err != nil {
+				return nil, err
+			}
+
+			created := statErr != nil && flag&os.O_CREATE != 0
+
+			if created || flag&os.O_TRUNC != 0 {
+				c.touch(name, created)
+			}
+
+			return c.file(name, f), nil
+		},
+		RemoveFunc: func(name string) error {
+			if err := fs.Remove(name); err != nil {
+				return err
+			}
+
+			c.remove(name)
+
+			return nil
+		},
+		RemoveAllFunc: func(path string) error {
+			if err := fs.RemoveAll(path); err != nil {
+				return err
+			}
+
+			c.remove(path)
+
+			return nil
+		},
+		RenameFunc: func(oldname, newname string) error {
+			if err := fs.Rename(oldname, newname); err != nil {
+				return err
+			}
+
+			c.rename(oldname, newname)
+
+			return nil
+		},
+		StatFunc: func(name string) (os.FileInfo, error) {
+			fi, err := fs.Stat(name)
+			if err != nil {
+				return nil, err
+			}
+
+			return c.fileInfo(name, fi), nil
+		},
+	})
+}
+
+type clockStamps struct {
+	clock Clock
+
+	mu     sync.RWMutex
+	mtimes map[string]time.Time
+}
+
+func (c *clockStamps) set(name string, mtime time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.mtimes[filepath.Clean(name)] = mtime
+}
+
+// touch sets the modification time of the file to the current time. When an entry is added to or removed from a
+// directory, the modification time of the directory is also updated.
+func (c *clockStamps) touch(name string, entryChanged bool) {
+	now := c.clock.Now()
+	name = filepath.Clean(name)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.mtimes[name] = now
+
+	if entryChanged {
+		c.mtimes[filepath.Dir(name)] = now
+	}
+}
+
+func (c *clockStamps) remove(path string) {
+	now := c.clock.Now()
+	path = filepath.Clean(path)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name := range c.mtimes {
+		if isSubPath(path, name) {
+			delete(c.mtimes, name)
+		}
+	}
+
+	c.mtimes[filepath.Dir(path)] = now
+}
+
+func (c *clockStamps) rename(oldname, newname string) {
+	now := c.clock.Now()
+	oldname = filepath.Clean(oldname)
+	newname = filepath.Clean(newname)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	moved := make(map[string]time.Time)
+
+	for name, mtime := range c.mtimes {
+		if isSubPath(oldname, name) {
+			moved[newname+strings.TrimPrefix(name, oldname)] = mtime
+
+			delete(c.mtimes, name)
+		}
+	}
+
+	for name := range c.mtimes {
+		if isSubPath(newname, name) {
+			delete(c.mtimes, name)
+		}
+	}
+
+	for name, mtime := range moved {
+		c.mtimes[name] = mtime
+	}
+
+	c.mtimes[filepath.Dir(oldname)] = now
+	c.mtimes[filepath.Dir(newname)] = now
+}
+
+func (c *clockStamps) fileInfo(name string, fi fs.FileInfo) fs.FileInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	mtime, ok := c.mtimes[filepath.Clean(name)]
+	if !ok {
+		return fi
+	}
+
+	return OverrideFileInfo(fi, FileInfoCallbacks{
+		ModTimeFunc: func() time.Time {
+			return mtime
+		},
+	})
+}
+
+func (c *clockStamps) file(name string, f afero.File) FileCallbacks {
+	return OverrideFile(f, FileCallbacks{
+		ReaddirFunc: func(count int) ([]fs.FileInfo, error) {
+			fis, err := f.Readdir(count)
+
+			for i, fi := range fis {
+				fis[i] = c.fileInfo(filepath.Join(name, fi.Name()), fi)
+			}
+
+			return fis, err
+		},
+		StatFunc: func() (fs.FileInfo, error) {
+			fi, err := f.Stat()
+			if err != nil {
+				return nil, err
+			}
+
+			return c.fileInfo(name, fi), nil
+		},
+		TruncateFunc: func(size int64) error {
+			if err := f.Truncate(size); err != nil {
+				return err
+			}
+
+			c.touch(name, false)
+
+			return nil
+		},
+		WriteFunc: func(p []byte) (int, error) {
+			n, err := f.Write(p)
+			if n > 0 {
+				c.touch(name, false)
+			}
+
+			return n, err
+		},
+		WriteAtFunc: func(p []byte, off int64) (int, error) {
+			n, err := f.WriteAt(p, off)
+			if n > 0 {
+				c.touch(name, false)
+			}
+
+			return n, err
+		},
+		WriteStringFunc: func(s string) (int, error) {
+			n, err := f.WriteString(s)
+			if n > 0 {
+				c.touch(name, false)
+			}
+
+			return n, err
+		},
+	})
+}
@@ -0,0 +1,124 @@
+package aferomock_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestFakeClock(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := aferomock.NewFakeClock(now)
+
+	assert.Equal(t, now, c.Now())
+
+	c.Advance(time.Hour)
+
+	assert.Equal(t, now.Add(time.Hour), c.Now())
+
+	c.Set(now)
+
+	assert.Equal(t, now, c.Now())
+}
+
+func TestClockFs(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := aferomock.NewFakeClock(start)
+	fs := aferomock.ClockFs(afero.NewMemMapFs(), c)
+
+	assertModTime := func(t *testing.T, name string, expected time.Time) {
+		t.Helper()
+
+		fi, err := fs.Stat(name)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, fi.ModTime(), name)
+	}
+
+	require.NoError(t, fs.MkdirAll("/data/logs", os.ModePerm))
+
+	assertModTime(t, "/data", start)
+	assertModTime(t, "/data/logs", start)
+
+	c.Advance(time.Minute)
+
+	f, err := fs.Create("/data/logs/app.log")
+	require.NoError(t, err)
+
+	assertModTime(t, "/data/logs/app.log", start.Add(time.Minute))
+	assertModTime(t, "/data/logs", start.Add(time.Minute))
+	assertModTime(t, "/data", start)
+
+	c.Advance(time.Minute)
+
+	_, err = f.WriteString("hello")
+	require.NoError(t, err)
+
+	fi, err := f.Stat()
+	require.NoError(t, err)
+
+	assert.Equal(t, start.Add(2*time.Minute), fi.ModTime())
+	assertModTime(t, "/data/logs", start.Add(time.Minute))
+
+	require.NoError(t, f.Close())
+
+	mtime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, fs.Chtimes("/data/logs/app.log", mtime, mtime))
+
+	assertModTime(t, "/data/logs/app.log", mtime)
+
+	c.Advance(time.Minute)
+
+	require.NoError(t, fs.Rename("/data/logs/app.log", "/data/app.log"))
+
+	assertModTime(t, "/data/app.log", mtime)
+	assertModTime(t, "/data/logs", start.Add(3*time.Minute))
+	assertModTime(t, "/data", start.Add(3*time.Minute))
+
+	d, err := fs.Open("/data")
+	require.NoError(t, err)
+
+	fis, err := d.Readdir(-1)
+	require.NoError(t, err)
+	require.NoError(t, d.Close())
+
+	actual := make(map[string]time.Time, len(fis))
+
+	for _, fi := range fis {
+		actual[fi.Name()] = fi.ModTime()
+	}
+
+	expected := map[string]time.Time{
+		"app.log": mtime,
+		"logs":    start.Add(3 * time.Minute),
+	}
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestClockFs_Upstream(t *testing.T) {
+	t.Parallel()
+
+	fi := aferomock.NopFileInfo(t)
+	c := aferomock.NewFakeClock(time.Now())
+
+	fs := aferomock.ClockFs(aferomock.MockFs(func(fs *aferomock.Fs) {
+		fs.On("Stat", "unknown").Return(fi, nil)
+	})(t), c)
+
+	actual, err := fs.Stat("unknown")
+	require.NoError(t, err)
+
+	assert.Equal(t, fi, actual)
+}
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	aferoPath     = "github.com/spf13/afero"
+	aferomockPath = "go.nhat.io/aferomock"
+	mockPath      = "github.com/stretchr/testify/mock"
+)
+
+var (
+	errNotInterface   = errors.New("not an interface")
+	errNotAferoFs     = errors.New("interface does not embed afero.Fs")
+	errTypeParameters = errors.New("interface with type parameters is not supported")
+)
+
+// config is the configuration of the generator.
+type config struct {
+	// Dir is the directory to resolve a relative package from.
+	Dir string
+	// Package is the import path of the package of the interface.
+	Package string
+	// Name is the name of the interface.
+	Name string
+	// MockName is the name of the mock type, the interface name is used if empty.
+	MockName string
+	// OutPackage is the package name of the generated code.
+	OutPackage string
+}
+
+// generate generates a mock, callbacks and a mocker for an interface that embeds afero.Fs.
+func generate(cfg config) ([]byte, error) {
+	fset := token.NewFileSet()
+	imp := importer.ForCompiler(fset, "source", nil).(types.ImporterFrom) //nolint: errcheck
+
+	// A relative package, such as ".", is resolved to its import path so that the generated code can import it.
+	if build.IsLocalImport(cfg.Package) {
+		path, err := resolveImportPath(cfg.Dir, cfg.Package)
+		if err != nil {
+			return nil, fmt.Errorf("could not find package %q: %w", cfg.Package, err)
+		}
+
+		cfg.Package = path
+	}
+
+	pkg, err := imp.ImportFrom(cfg.Package, cfg.Dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not load package %q: %w", cfg.Package, err)
+	}
+
+	afero, err := imp.ImportFrom(aferoPath, cfg.Dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not load package %q: %w", aferoPath, err)
+	}
+
+	iface, err := lookupInterface(pkg, cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	aferoFs := afero.Scope().Lookup("Fs").Type().Underlying().(*types.Interface) //nolint: errcheck
+
+	if !types.Implements(iface, aferoFs) {
+		return nil, fmt.Errorf("%s.%s: %w", pkg.Path(), cfg.Name, errNotAferoFs)
+	}
+
+	if cfg.MockName == "" {
+		cfg.MockName = cfg.Name
+	}
+
+	g := &generator{
+		cfg:     cfg,
+		pkg:     pkg,
+		imports: make(map[string]string),
+	}
+
+	for i := range iface.NumMethods() {
+		m := iface.Method(i)
+
+		if obj, _, _ := types.LookupFieldOrMethod(aferoFs, false, m.Pkg(), m.Name()); obj != nil {
+			continue
+		}
+
+		g.methods = append(g.methods, m)
+	}
+
+	return g.generate()
+}
+
+// resolveImportPath resolves the import path of a relative package with go list, go/build does not support relative
+// packages in module mode.
+func resolveImportPath(dir, pkg string) (string, error) {
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}}", pkg)
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+func lookupInterface(pkg *types.Package, name string) (*types.Interface, error) {
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%s.%s: %w", pkg.Path(), name, errNotInterface)
+	}
+
+	if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+		return nil, fmt.Errorf("%s.%s: %w", pkg.Path(), name, errTypeParameters)
+	}
+
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s.%s: %w", pkg.Path(), name, errNotInterface)
+	}
+
+	return iface, nil
+}
+
+type generator struct {
+	cfg     config
+	pkg     *types.Package
+	methods []*types.Func
+	imports map[string]string
+
+	buf bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(&g.buf, format, args...)
+}
+
+// qualifier returns the name of the package in the generated code, and adds the package to the imports.
+func (g *generator) qualifier(pkg *types.Package) string {
+	return g.importPackage(pkg.Path(), pkg.Name())
+}
+
+func (g *generator) importPackage(path, name string) string {
+	if alias, ok := g.imports[path]; ok {
+		return alias
+	}
+
+	alias := name
+
+	for i := 2; g.aliasUsed(alias); i++ {
+		alias = name + strconv.Itoa(i)
+	}
+
+	g.imports[path] = alias
+
+	return alias
+}
+
+func (g *generator) aliasUsed(alias string) bool {
+	for _, a := range g.imports {
+		if a == alias {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+func (g *generator) generate() ([]byte, error) {
+	mockPkg := g.importPackage(mockPath, "mock")
+	aferomockPkg := g.importPackage(aferomockPath, "aferomock")
+	testingPkg := g.importPackage("testing", "testing")
+	iface := g.qualifier(g.pkg) + "." + g.cfg.Name
+	name := g.cfg.MockName
+
+	g.printf("var _ %s = (*%s)(nil)\n\n", iface, name)
+	g.printf("// %s is a mock for %s.\n", name, iface)
+	g.printf("type %s struct {\n%s.Fs\n}\n\n", name, aferomockPkg)
+
+	for _, m := range g.methods {
+		g.generateMockMethod(m)
+	}
+
+	g.printf("// New%s creates a new instance of %s. It also registers a testing interface on the mock and a cleanup "+
+		"function to assert the mocks expectations.\n", name, name)
+	g.printf("func New%s(t interface {\n%s.TestingT\nCleanup(func())\n}) *%s {\n", name, mockPkg, name)
+	g.printf("m := &%s{}\nm.Mock.Test(t)\n\nt.Cleanup(func() { m.AssertExpectations(t) })\n\nreturn m\n}\n\n", name)
+
+	g.printf("// %sMocker is %s mocker.\n", name, name)
+	g.printf("type %sMocker func(tb %s.TB) *%s\n\n", name, testingPkg, name)
+	g.printf("// Nop%s is no mock %s.\n", name, name)
+	g.printf("var Nop%s = Mock%s()\n\n", name, name)
+	g.printf("// Mock%s creates %s mock with cleanup to ensure all the expectations are met.\n", name, name)
+	g.printf("func Mock%s(mocks ...func(m *%s)) %sMocker {\n", name, name, name)
+	g.printf("return func(tb %s.TB) *%s {\ntb.Helper()\n\nm := New%s(tb)\n\n", testingPkg, name, name)
+	g.printf("for _, fn := range mocks {\nfn(m)\n}\n\n")
+	g.printf("m.On(\"Name\").Maybe().\nReturn(%q)\n\nreturn m\n}\n}\n\n", "aferomock."+name)
+
+	g.printf("var _ %s = %sCallbacks{}\n\n", iface, name)
+	g.printf("// %sCallbacks is a callback-based mock for %s.\n", name, iface)
+	g.printf("type %sCallbacks struct {\n%s.FsCallbacks\n\n", name, aferomockPkg)
+
+	for _, m := range g.methods {
+		g.printf("%sFunc %s\n", m.Name(), g.funcType(m.Type().(*types.Signature))) //nolint: forcetypeassert
+	}
+
+	g.printf("}\n\n")
+
+	for _, m := range g.methods {
+		sig := m.Type().(*types.Signature) //nolint: errcheck
+		params := g.params(sig)
+
+		g.printf("// %s satisfies the %s interface.\n", m.Name(), iface)
+		g.printf("func (c %sCallbacks) %s%s {\n", name, m.Name(), g.signature(sig, params))
+
+		if sig.Results().Len() > 0 {
+			g.printf("return ")
+		}
+
+		g.printf("c.%sFunc(%s)\n}\n\n", m.Name(), callArgs(sig, params))
+	}
+
+	g.printf("// Override%s overrides a %s with custom callbacks.\n", name, iface)
+	g.printf("func Override%s(base %s, c %sCallbacks) %sCallbacks {\n", name, iface, name, name)
+	g.printf("c.FsCallbacks = %s.OverrideFs(base, c.FsCallbacks)\n\n", aferomockPkg)
+
+	for _, m := range g.methods {
+		g.printf("if c.%sFunc == nil {\nc.%sFunc = base.%s\n}\n\n", m.Name(), m.Name(), m.Name())
+	}
+
+	g.printf("return c\n}\n")
+
+	return g.source()
+}
+
+func (g *generator) source() ([]byte, error) {
+	paths := make([]string, 0, len(g.imports))
+
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+
+	sort.Slice(paths, func(i, j int) bool {
+		si, sj := isStd(paths[i]), isStd(paths[j])
+		if si != sj {
+			return si
+		}
+
+		return paths[i] < paths[j]
+	})
+
+	var out bytes.Buffer
+
+	_, _ = fmt.Fprintf(&out, "// Code generated by aferomock. DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.cfg.OutPackage)
+
+	for i, path := range paths {
+		if i > 0 && isStd(paths[i-1]) && !isStd(path) {
+			out.WriteString("\n")
+		}
+
+		if alias := g.imports[path]; alias != path[strings.LastIndex(path, "/")+1:] {
+			_, _ = fmt.Fprintf(&out, "%s %q\n", alias, path)
+		} else {
+			_, _ = fmt.Fprintf(&out, "%q\n", path)
+		}
+	}
+
+	out.WriteString(")\n\n")
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("could not format the generated code: %w", err)
+	}
+
+	return src, nil
+}
+
+func isStd(path string) bool {
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
+
+// params returns the names of the parameters, the unnamed parameters are named _a0, _a1, etc.
+func (g *generator) params(sig *types.Signature) []string {
+	names := make([]string, sig.Params().Len())
+
+	for i := range names {
+		names[i] = sig.Params().At(i).Name()
+
+		if names[i] == "" || names[i] == "_" {
+			names[i] = fmt.Sprintf("_a%d", i)
+		}
+	}
+
+	return names
+}
+
+func (g *generator) paramTypes(sig *types.Signature) []string {
+	result := make([]string, sig.Params().Len())
+
+	for i := range result {
+		t := sig.Params().At(i).Type()
+
+		if sig.Variadic() && i == len(result)-1 {
+			result[i] = "..." + g.typeString(t.(*types.Slice).Elem()) //nolint: forcetypeassert
+		} else {
+			result[i] = g.typeString(t)
+		}
+	}
+
+	return result
+}
+
+func (g *generator) resultTypes(sig *types.Signature) []string {
+	result := make([]string, sig.Results().Len())
+
+	for i := range result {
+		result[i] = g.typeString(sig.Results().At(i).Type())
+	}
+
+	return result
+}
+
+func joinResults(results []string) string {
+	switch len(results) {
+	case 0:
+		return ""
+
+	case 1:
+		return " " + results[0]
+
+	default:
+		return " (" + strings.Join(results, ", ") + ")"
+	}
+}
+
+// signature returns the signature of the method with the given parameter names.
+func (g *generator) signature(sig *types.Signature, params []string) string {
+	paramTypes := g.paramTypes(sig)
+	named := make([]string, len(params))
+
+	for i := range params {
+		named[i] = params[i] + " " + paramTypes[i]
+	}
+
+	return "(" + strings.Join(named, ", ") + ")" + joinResults(g.resultTypes(sig))
+}
+
+func (g *generator) funcType(sig *types.Signature) string {
+	return "func" + g.signature(sig, g.params(sig))
+}
+
+func callArgs(sig *types.Signature, params []string) string {
+	args := strings.Join(params, ", ")
+
+	if sig.Variadic() {
+		args += "..."
+	}
+
+	return args
+}
+
+func (g *generator) generateMockMethod(m *types.Func) { //nolint: funlen
+	sig := m.Type().(*types.Signature) //nolint: errcheck
+	params := g.params(sig)
+	paramTypes := "(" + strings.Join(g.paramTypes(sig), ", ") + ")"
+	results := g.resultTypes(sig)
+
+	if len(params) == 0 {
+		g.printf("// %s provides a mock function with no fields\n", m.Name())
+	} else {
+		g.printf("// %s provides a mock function with given fields: %s\n", m.Name(), strings.Join(params, ", "))
+	}
+
+	g.printf("func (_m *%s) %s%s {\n", g.cfg.MockName, m.Name(), g.signature(sig, params))
+
+	called := strings.Join(params, ", ")
+
+	if sig.Variadic() {
+		last := params[len(params)-1]
+
+		g.printf("_va := make([]interface{}, len(%s))\nfor _i := range %s {\n_va[_i] = %s[_i]\n}\n", last, last, last)
+		g.printf("var _ca []interface{}\n")
+
+		if len(params) > 1 {
+			g.printf("_ca = append(_ca, %s)\n", strings.Join(params[:len(params)-1], ", "))
+		}
+
+		g.printf("_ca = append(_ca, _va...)\n")
+
+		called = "_ca..."
+	}
+
+	if len(results) == 0 {
+		g.printf("_m.Called(%s)\n}\n\n", called)
+
+		return
+	}
+
+	g.printf("ret := _m.Called(%s)\n\n", called)
+	g.printf("if len(ret) == 0 {\npanic(%q)\n}\n\n", "no return value specified for "+m.Name())
+
+	for i, r := range results {
+		g.printf("var r%d %s\n", i, r)
+	}
+
+	args := callArgs(sig, params)
+
+	if len(results) > 1 {
+		g.printf("if rf, ok := ret.Get(0).(func%s%s); ok {\nreturn rf(%s)\n}\n", paramTypes, joinResults(results), args)
+	}
+
+	for i, r := range results {
+		g.printf("if rf, ok := ret.Get(%d).(func%s %s); ok {\nr%d = rf(%s)\n} else {\n", i, paramTypes, r, i, args)
+
+		t := sig.Results().At(i).Type()
+
+		switch {
+		case isError(t):
+			g.printf("r%d = ret.Error(%d)\n", i, i)
+
+		case isNillable(t):
+			g.printf("if ret.Get(%d) != nil {\nr%d = ret.Get(%d).(%s)\n}\n", i, i, i, r)
+
+		default:
+			g.printf("r%d = ret.Get(%d).(%s)\n", i, i, r)
+		}
+
+		g.printf("}\n\n")
+	}
+
+	r := make([]string, len(results))
+
+	for i := range r {
+		r[i] = fmt.Sprintf("r%d", i)
+	}
+
+	g.printf("return %s\n}\n\n", strings.Join(r, ", "))
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+func isNillable(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return true
+	}
+
+	return false
+}
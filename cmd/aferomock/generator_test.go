@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate_Golden(t *testing.T) {
+	t.Parallel()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	actual, err := generate(config{
+		Dir:        filepath.Join(wd, "internal", "example"),
+		Package:    ".",
+		Name:       "Storage",
+		OutPackage: "mocks",
+	})
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile(filepath.Join("internal", "example", "mocks", "storage.go"))
+	require.NoError(t, err)
+
+	assert.Equal(t, string(expected), string(actual), "the generated mock is outdated, run go generate ./...")
+}
+
+func TestGenerate_Error(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		pkg           string
+		name          string
+		expectedError error
+	}{
+		{
+			scenario:      "not found",
+			pkg:           "go.nhat.io/aferomock/cmd/aferomock/internal/example",
+			name:          "Unknown",
+			expectedError: errNotInterface,
+		},
+		{
+			scenario:      "not an interface",
+			pkg:           "github.com/spf13/afero",
+			name:          "MemMapFs",
+			expectedError: errNotInterface,
+		},
+		{
+			scenario:      "not an afero.Fs",
+			pkg:           "github.com/spf13/afero",
+			name:          "File",
+			expectedError: errNotAferoFs,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := generate(config{Package: tc.pkg, Name: tc.name, OutPackage: "mocks"})
+
+			require.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestRun_MissingName(t *testing.T) {
+	t.Parallel()
+
+	err := run(nil)
+
+	require.ErrorIs(t, err, errMissingName)
+}
+
+func TestRun_Output(t *testing.T) {
+	t.Parallel()
+
+	output := filepath.Join(t.TempDir(), "mocks", "fs.go")
+
+	err := run([]string{"-pkg", "github.com/spf13/afero", "-name", "Fs", "-mockname", "AferoFs", "-output", output})
+	require.NoError(t, err)
+
+	src, err := os.ReadFile(filepath.Clean(output))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(src), "type AferoFs struct {\n\taferomock.Fs\n}")
+	assert.Contains(t, string(src), "func MockAferoFs(mocks ...func(m *AferoFs)) AferoFsMocker {")
+}
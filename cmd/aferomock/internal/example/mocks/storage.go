@@ -0,0 +1,273 @@
+// Code generated by aferomock. DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"go.nhat.io/aferomock"
+	"go.nhat.io/aferomock/cmd/aferomock/internal/example"
+)
+
+var _ example.Storage = (*Storage)(nil)
+
+// Storage is a mock for example.Storage.
+type Storage struct {
+	aferomock.Fs
+}
+
+// LstatIfPossible provides a mock function with given fields: name
+func (_m *Storage) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LstatIfPossible")
+	}
+
+	var r0 os.FileInfo
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string) (os.FileInfo, bool, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) os.FileInfo); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(os.FileInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(name)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Quota provides a mock function with given fields: ctx
+func (_m *Storage) Quota(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Quota")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadlinkIfPossible provides a mock function with given fields: name
+func (_m *Storage) ReadlinkIfPossible(name string) (string, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadlinkIfPossible")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reset provides a mock function with no fields
+func (_m *Storage) Reset() {
+	_m.Called()
+}
+
+// SymlinkIfPossible provides a mock function with given fields: oldname, newname
+func (_m *Storage) SymlinkIfPossible(oldname string, newname string) error {
+	ret := _m.Called(oldname, newname)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SymlinkIfPossible")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(oldname, newname)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Touch provides a mock function with given fields: ctx, names
+func (_m *Storage) Touch(ctx context.Context, names ...string) error {
+	_va := make([]interface{}, len(names))
+	for _i := range names {
+		_va[_i] = names[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Touch")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) error); ok {
+		r0 = rf(ctx, names...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Storage {
+	m := &Storage{}
+	m.Mock.Test(t)
+
+	t.Cleanup(func() { m.AssertExpectations(t) })
+
+	return m
+}
+
+// StorageMocker is Storage mocker.
+type StorageMocker func(tb testing.TB) *Storage
+
+// NopStorage is no mock Storage.
+var NopStorage = MockStorage()
+
+// MockStorage creates Storage mock with cleanup to ensure all the expectations are met.
+func MockStorage(mocks ...func(m *Storage)) StorageMocker {
+	return func(tb testing.TB) *Storage {
+		tb.Helper()
+
+		m := NewStorage(tb)
+
+		for _, fn := range mocks {
+			fn(m)
+		}
+
+		m.On("Name").Maybe().
+			Return("aferomock.Storage")
+
+		return m
+	}
+}
+
+var _ example.Storage = StorageCallbacks{}
+
+// StorageCallbacks is a callback-based mock for example.Storage.
+type StorageCallbacks struct {
+	aferomock.FsCallbacks
+
+	LstatIfPossibleFunc    func(name string) (os.FileInfo, bool, error)
+	QuotaFunc              func(ctx context.Context) (int64, error)
+	ReadlinkIfPossibleFunc func(name string) (string, error)
+	ResetFunc              func()
+	SymlinkIfPossibleFunc  func(oldname string, newname string) error
+	TouchFunc              func(ctx context.Context, names ...string) error
+}
+
+// LstatIfPossible satisfies the example.Storage interface.
+func (c StorageCallbacks) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	return c.LstatIfPossibleFunc(name)
+}
+
+// Quota satisfies the example.Storage interface.
+func (c StorageCallbacks) Quota(ctx context.Context) (int64, error) {
+	return c.QuotaFunc(ctx)
+}
+
+// ReadlinkIfPossible satisfies the example.Storage interface.
+func (c StorageCallbacks) ReadlinkIfPossible(name string) (string, error) {
+	return c.ReadlinkIfPossibleFunc(name)
+}
+
+// Reset satisfies the example.Storage interface.
+func (c StorageCallbacks) Reset() {
+	c.ResetFunc()
+}
+
+// SymlinkIfPossible satisfies the example.Storage interface.
+func (c StorageCallbacks) SymlinkIfPossible(oldname string, newname string) error {
+	return c.SymlinkIfPossibleFunc(oldname, newname)
+}
+
+// Touch satisfies the example.Storage interface.
+func (c StorageCallbacks) Touch(ctx context.Context, names ...string) error {
+	return c.TouchFunc(ctx, names...)
+}
+
+// OverrideStorage overrides a example.Storage with custom callbacks.
+func OverrideStorage(base example.Storage, c StorageCallbacks) StorageCallbacks {
+	c.FsCallbacks = aferomock.OverrideFs(base, c.FsCallbacks)
+
+	if c.LstatIfPossibleFunc == nil {
+		c.LstatIfPossibleFunc = base.LstatIfPossible
+	}
+
+	if c.QuotaFunc == nil {
+		c.QuotaFunc = base.Quota
+	}
+
+	if c.ReadlinkIfPossibleFunc == nil {
+		c.ReadlinkIfPossibleFunc = base.ReadlinkIfPossible
+	}
+
+	if c.ResetFunc == nil {
+		c.ResetFunc = base.Reset
+	}
+
+	if c.SymlinkIfPossibleFunc == nil {
+		c.SymlinkIfPossibleFunc = base.SymlinkIfPossible
+	}
+
+	if c.TouchFunc == nil {
+		c.TouchFunc = base.Touch
+	}
+
+	return c
+}
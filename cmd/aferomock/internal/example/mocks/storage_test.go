@@ -0,0 +1,78 @@
+package mocks_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock/cmd/aferomock/internal/example"
+	"go.nhat.io/aferomock/cmd/aferomock/internal/example/mocks"
+)
+
+type storage struct {
+	afero.Fs
+	afero.Symlinker
+}
+
+func (storage) Quota(context.Context) (int64, error) {
+	return 42, nil
+}
+
+func (storage) Touch(context.Context, ...string) error {
+	return nil
+}
+
+func (storage) Reset() {}
+
+func TestStorage(t *testing.T) {
+	t.Parallel()
+
+	s := mocks.MockStorage(func(m *mocks.Storage) {
+		m.On("Mkdir", "data", os.ModePerm).Return(nil)
+		m.On("Quota", context.Background()).Return(int64(10), nil)
+		m.On("Touch", context.Background(), "a", "b").Return(errors.New("touch error"))
+		m.On("Reset").Once()
+	})(t)
+
+	var st example.Storage = s
+
+	require.NoError(t, st.Mkdir("data", os.ModePerm))
+
+	quota, err := st.Quota(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), quota)
+
+	require.EqualError(t, st.Touch(context.Background(), "a", "b"), "touch error")
+
+	st.Reset()
+
+	assert.Equal(t, "aferomock.Storage", st.Name())
+}
+
+func TestOverrideStorage(t *testing.T) {
+	t.Parallel()
+
+	memFs := afero.NewMemMapFs()
+	base := storage{Fs: memFs, Symlinker: afero.NewOsFs().(afero.Symlinker)} //nolint: errcheck,forcetypeassert
+
+	s := mocks.OverrideStorage(base, mocks.StorageCallbacks{
+		QuotaFunc: func(context.Context) (int64, error) {
+			return 0, errors.New("quota error")
+		},
+	})
+
+	_, err := s.Quota(context.Background())
+	require.EqualError(t, err, "quota error")
+
+	require.NoError(t, s.Touch(context.Background()))
+	require.NoError(t, s.Mkdir("data", os.ModePerm))
+
+	fi, err := memFs.Stat("data")
+	require.NoError(t, err)
+	assert.True(t, fi.IsDir())
+}
@@ -0,0 +1,20 @@
+// Package example provides an interface to test the aferomock command.
+package example
+
+import (
+	"context"
+
+	"github.com/spf13/afero"
+)
+
+//go:generate go run go.nhat.io/aferomock/cmd/aferomock -name Storage -output mocks/storage.go
+
+// Storage is an afero.Fs with extra methods.
+type Storage interface {
+	afero.Fs
+	afero.Symlinker
+
+	Quota(ctx context.Context) (int64, error)
+	Touch(ctx context.Context, names ...string) error
+	Reset()
+}
@@ -0,0 +1,74 @@
+// Command aferomock generates mocks for interfaces that embed afero.Fs.
+//
+// The generated mock reuses aferomock.Fs for the methods of afero.Fs, and provides a callbacks struct with an
+// Override function and a Mock function in the style of the aferomock package.
+//
+//	//go:generate go run go.nhat.io/aferomock/cmd/aferomock -name Storage -output mocks/storage.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var errMissingName = errors.New("missing interface name, use -name")
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "aferomock:", err)
+
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("aferomock", flag.ContinueOnError)
+
+	cfg := config{}
+	output := ""
+
+	flags.StringVar(&cfg.Name, "name", "", "name of the interface to mock")
+	flags.StringVar(&cfg.Package, "pkg", ".", "import path of the package of the interface")
+	flags.StringVar(&cfg.MockName, "mockname", "", "name of the mock type, default to the name of the interface")
+	flags.StringVar(&cfg.OutPackage, "outpkg", "mocks", "package name of the generated code")
+	flags.StringVar(&output, "output", "", "output file, default to stdout")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if cfg.Name == "" {
+		return errMissingName
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("could not get working directory: %w", err)
+	}
+
+	cfg.Dir = dir
+
+	src, err := generate(cfg)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return fmt.Errorf("could not create output directory: %w", err)
+	}
+
+	if err := os.WriteFile(output, src, 0o644); err != nil { //nolint: gosec
+		return fmt.Errorf("could not write output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+package aferomock
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// ConcurrencyCheckFs wraps an afero.Fs and fails the test when it detects concurrent accesses that are likely to be
+// data races:
+//
+//   - Overlapping calls on the same file handle from different goroutines, such as a Seek racing with a Read, when
+//     one of them uses the offset of the handle or changes its state: Read, Write, WriteString, Seek, Readdir,
+//     Readdirnames, Truncate and Close. The positional calls ReadAt and WriteAt, and Stat, may overlap with each
+//     other, like io.ReaderAt and io.WriterAt allow.
+//   - Remove, RemoveAll or Rename on a path while another goroutine is operating on that path, its parent or one of
+//     its children, including while another goroutine holds the file open for writing.
+//
+// The failure message contains the stack traces of both goroutines.
+func ConcurrencyCheckFs(tb testing.TB, fs afero.Fs) FsCallbacks { //nolint: funlen
+	tb.Helper()
+
+	cc := &concurrencyChecker{
+		tb:         tb,
+		activities: make(map[*activity]struct{}),
+	}
+
+	return OverrideFs(fs, FsCallbacks{
+		ChmodFunc: func(name string, mode os.FileMode) error {
+			defer cc.begin("Chmod", false, nil, name)()
+
+			return fs.Chmod(name, mode)
+		},
+		ChownFunc: func(name string, uid, gid int) error {
+			defer cc.begin("Chown", false, nil, name)()
+
+			return fs.Chown(name, uid, gid)
+		},
+		ChtimesFunc: func(name string, atime, mtime time.Time) error {
+			defer cc.begin("Chtimes", false, nil, name)()
+
+			return fs.Chtimes(name, atime, mtime)
+		},
+		CreateFunc: func(name string) (afero.File, error) {
+			defer cc.begin("Create", false, nil, name)()
+
+			return cc.track(name, true)(fs.Create(name))
+		},
+		MkdirFunc: func(name string, perm os.FileMode) error {
+			defer cc.begin("Mkdir", false, nil, name)()
+
+			return fs.Mkdir(name, perm)
+		},
+		MkdirAllFunc: func(path string, perm os.FileMode) error {
+			defer cc.begin("MkdirAll", false, nil, path)()
+
+			return fs.MkdirAll(path, perm)
+		},
+		OpenFunc: func(name string) (afero.File, error) {
+			defer cc.begin("Open", false, nil, name)()
+
+			return cc.track(name, false)(fs.Open(name))
+		},
+		OpenFileFunc: func(name string, flag int, perm os.FileMode) (afero.File, error) {
+			defer cc.begin("OpenFile", false, nil, name)()
+
+			return cc.track(name, flag&(os.O_WRONLY|os.O_RDWR) != 0)(fs.OpenFile(name, flag, perm))
+		},
+		RemoveFunc: func(name string) error {
+			defer cc.begin("Remove", true, nil, name)()
+
+			return fs.Remove(name)
+		},
+		RemoveAllFunc: func(path string) error {
+			defer cc.begin("RemoveAll", true, nil, path)()
+
+			return fs.RemoveAll(path)
+		},
+		RenameFunc: func(oldname, newname string) error {
+			defer cc.begin("Rename", true, nil, oldname, newname)()
+
+			return fs.Rename(oldname, newname)
+		},
+		StatFunc: func(name string) (os.FileInfo, error) {
+			defer cc.begin("Stat", false, nil, name)()
+
+			return fs.Stat(name)
+		},
+	})
+}
+
+// handleStateOps are the methods of afero.File that use the offset of the handle or change its state, they must not
+// overlap with another call on the same handle.
+var handleStateOps = map[string]bool{
+	"Close": true, "Read": true, "Readdir": true, "Readdirnames": true, "Seek": true, "Truncate": true, "Write": true,
+	"WriteString": true,
+}
+
+// activity is an operation in progress on one or more paths.
+type activity struct {
+	op          string
+	paths       []string
+	handle      *concurrentHandle
+	destructive bool
+	goroutine   uint64
+	stack       string
+}
+
+type concurrentHandle struct {
+	name string
+}
+
+type concurrencyChecker struct {
+	tb testing.TB
+
+	mu         sync.Mutex
+	activities map[*activity]struct{}
+}
+
+// begin registers an activity and reports the conflicts with the other activities. It returns a function to end the
+// activity.
+func (cc *concurrencyChecker) begin(op string, destructive bool, h *concurrentHandle, paths ...string) func() {
+	a := &activity{
+		op:          op,
+		paths:       make([]string, len(paths)),
+		handle:      h,
+		destructive: destructive,
+		goroutine:   goroutineID(),
+		stack:       callerStack(),
+	}
+
+	for i, p := range paths {
+		a.paths[i] = filepath.Clean(p)
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	for b := range cc.activities {
+		if b.goroutine == a.goroutine {
+			continue
+		}
+
+		if reason, ok := conflicts(a, b); ok {
+			cc.tb.Errorf("%s\ngoroutine %d, %s at:\n%sgoroutine %d, %s at:\n%s",
+				reason, a.goroutine, a.op, a.stack, b.goroutine, b.op, b.stack)
+		}
+	}
+
+	cc.activities[a] = struct{}{}
+
+	return func() {
+		cc.mu.Lock()
+		defer cc.mu.Unlock()
+
+		delete(cc.activities, a)
+	}
+}
+
+func conflicts(a, b *activity) (string, bool) {
+	if a.handle != nil && a.handle == b.handle && (handleStateOps[a.op] || handleStateOps[b.op]) {
+		return fmt.Sprintf("concurrent calls on file %q: %s and %s", a.handle.name, a.op, b.op), true
+	}
+
+	if !a.destructive && !b.destructive {
+		return "", false
+	}
+
+	for _, p := range a.paths {
+		for _, q := range b.paths {
+			if isSubPath(p, q) || isSubPath(q, p) {
+				return fmt.Sprintf("concurrent operations on path %q: %s and %s on %q", p, a.op, b.op, q), true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// track wraps the opened file to check the concurrent calls on it. A file opened for writing is an activity on its
+// path until it is closed.
+func (cc *concurrencyChecker) track(name string, write bool) func(afero.File, error) (afero.File, error) {
+	return func(f afero.File, err error) (afero.File, error) {
+		if err != nil {
+			return f, err
+		}
+
+		h := &concurrentHandle{name: name}
+
+		endWrite := func() {}
+
+		if write {
+			endWrite = cc.begin("open for writing", false, nil, name)
+		}
+
+		var closeOnce sync.Once
+
+		return OverrideFile(f, FileCallbacks{
+			CloseFunc: func() error {
+				defer cc.begin("Close", false, h, name)()
+
+				closeOnce.Do(endWrite)
+
+				return f.Close()
+			},
+			ReadFunc: func(p []byte) (int, error) {
+				defer cc.begin("Read", false, h, name)()
+
+				return f.Read(p)
+			},
+			ReadAtFunc: func(p []byte, off int64) (int, error) {
+				defer cc.begin("ReadAt", false, h, name)()
+
+				return f.ReadAt(p, off)
+			},
+			ReaddirFunc: func(count int) ([]fs.FileInfo, error) {
+				defer cc.begin("Readdir", false, h, name)()
+
+				return f.Readdir(count)
+			},
+			ReaddirnamesFunc: func(n int) ([]string, error) {
+				defer cc.begin("Readdirnames", false, h, name)()
+
+				return f.Readdirnames(n)
+			},
+			SeekFunc: func(offset int64, whence int) (int64, error) {
+				defer cc.begin("Seek", false, h, name)()
+
+				return f.Seek(offset, whence)
+			},
+			StatFunc: func() (fs.FileInfo, error) {
+				defer cc.begin("Stat", false, h, name)()
+
+				return f.Stat()
+			},
+			SyncFunc: func() error {
+				defer cc.begin("Sync", false, h, name)()
+
+				return f.Sync()
+			},
+			TruncateFunc: func(size int64) error {
+				defer cc.begin("Truncate", false, h, name)()
+
+				return f.Truncate(size)
+			},
+			WriteFunc: func(p []byte) (int, error) {
+				defer cc.begin("Write", false, h, name)()
+
+				return f.Write(p)
+			},
+			WriteAtFunc: func(p []byte, off int64) (int, error) {
+				defer cc.begin("WriteAt", false, h, name)()
+
+				return f.WriteAt(p, off)
+			},
+			WriteStringFunc: func(s string) (int, error) {
+				defer cc.begin("WriteString", false, h, name)()
+
+				return f.WriteString(s)
+			},
+		}), nil
+	}
+}
@@ -0,0 +1,232 @@
+package aferomock_test
+
+import (
+	"io/fs"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+// blockingFs returns a fs whose files block in Read, ReadAt and Readdir until release is closed. The entered channel receives
+// a value when a file starts blocking.
+func blockingFs() (afero.Fs, chan struct{}, chan struct{}) {
+	base := afero.NewMemMapFs()
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	_ = base.MkdirAll("/data", os.ModePerm)                             //nolint: errcheck
+	_ = afero.WriteFile(base, "/data/file.txt", []byte("hello"), 0o644) //nolint: errcheck
+
+	wrap := func(f afero.File, err error) (afero.File, error) {
+		if err != nil {
+			return nil, err
+		}
+
+		return aferomock.OverrideFile(f, aferomock.FileCallbacks{
+			ReadFunc: func(p []byte) (int, error) {
+				entered <- struct{}{}
+				<-release
+
+				return f.Read(p)
+			},
+			ReadAtFunc: func(p []byte, off int64) (int, error) {
+				entered <- struct{}{}
+				<-release
+
+				return f.ReadAt(p, off)
+			},
+			ReaddirFunc: func(count int) ([]fs.FileInfo, error) {
+				entered <- struct{}{}
+				<-release
+
+				return f.Readdir(count)
+			},
+		}), nil
+	}
+
+	return aferomock.OverrideFs(base, aferomock.FsCallbacks{
+		OpenFunc: func(name string) (afero.File, error) {
+			return wrap(base.Open(name))
+		},
+	}), entered, release
+}
+
+func TestConcurrencyCheckFs_SameHandle(t *testing.T) {
+	t.Parallel()
+
+	base, entered, release := blockingFs()
+	ft := newFakeT(t)
+	fs := aferomock.ConcurrencyCheckFs(ft, base)
+
+	f, err := fs.Open("/data/file.txt")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		_, _ = f.Read(make([]byte, 5)) //nolint: errcheck
+	}()
+
+	<-entered
+
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err)
+
+	close(release)
+	wg.Wait()
+
+	require.NoError(t, f.Close())
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], `concurrent calls on file "/data/file.txt": Seek and Read`)
+	assert.Contains(t, errs[0], ", Seek at:\n\tgo.nhat.io/aferomock_test.TestConcurrencyCheckFs_SameHandle\n")
+	assert.Contains(t, errs[0], ", Read at:\n\tgo.nhat.io/aferomock_test.TestConcurrencyCheckFs_SameHandle.func1\n")
+}
+
+func TestConcurrencyCheckFs_SameHandlePositional(t *testing.T) {
+	t.Parallel()
+
+	base, entered, release := blockingFs()
+	ft := newFakeT(t)
+	fs := aferomock.ConcurrencyCheckFs(ft, base)
+
+	f, err := fs.Open("/data/file.txt")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		_, _ = f.ReadAt(make([]byte, 2), 0) //nolint: errcheck
+	}()
+
+	<-entered
+
+	// The positional calls and Stat may overlap.
+	_, _ = f.WriteAt([]byte("x"), 4) //nolint: errcheck
+
+	_, err = f.Stat()
+	require.NoError(t, err)
+
+	assert.Empty(t, ft.Errors())
+
+	// A call that uses the offset of the handle may not.
+	_, err = f.Seek(1, 0)
+	require.NoError(t, err)
+
+	close(release)
+	wg.Wait()
+
+	require.NoError(t, f.Close())
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], `concurrent calls on file "/data/file.txt": Seek and ReadAt`)
+}
+
+func TestConcurrencyCheckFs_RemoveAllDuringReaddir(t *testing.T) {
+	t.Parallel()
+
+	base, entered, release := blockingFs()
+	ft := newFakeT(t)
+	fs := aferomock.ConcurrencyCheckFs(ft, base)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		f, err := fs.Open("/data")
+		if err != nil {
+			return
+		}
+
+		defer f.Close() //nolint: errcheck
+
+		_, _ = f.Readdir(-1) //nolint: errcheck
+	}()
+
+	<-entered
+
+	err := fs.RemoveAll("/data")
+	require.NoError(t, err)
+
+	close(release)
+	wg.Wait()
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], `concurrent operations on path "/data": RemoveAll and Readdir on "/data"`)
+}
+
+func TestConcurrencyCheckFs_RenameWhileWriterIsOpen(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+	fs := aferomock.ConcurrencyCheckFs(ft, afero.NewMemMapFs())
+
+	opened := make(chan afero.File)
+
+	go func() {
+		f, err := fs.Create("/data/file.txt")
+		if err != nil {
+			close(opened)
+
+			return
+		}
+
+		opened <- f
+	}()
+
+	f := <-opened
+	require.NotNil(t, f)
+
+	require.NoError(t, fs.Rename("/data", "/backup"))
+	require.NoError(t, f.Close())
+
+	// Once the writer is closed, there is no conflict.
+	require.NoError(t, fs.Rename("/backup", "/data"))
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], `concurrent operations on path "/data": Rename and open for writing on "/data/file.txt"`)
+}
+
+func TestConcurrencyCheckFs_SameGoroutine(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+	fs := aferomock.ConcurrencyCheckFs(ft, afero.NewMemMapFs())
+
+	f, err := fs.Create("/data/file.txt")
+	require.NoError(t, err)
+
+	_, err = f.WriteString("hello")
+	require.NoError(t, err)
+
+	require.NoError(t, fs.Rename("/data/file.txt", "/data/new.txt"))
+	require.NoError(t, f.Close())
+	require.NoError(t, fs.RemoveAll("/data"))
+
+	assert.Empty(t, ft.Errors())
+}
@@ -0,0 +1,341 @@
+package aferomock
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+var _ afero.Fs = (*CrashFs)(nil)
+
+// CrashOption configures CrashFs.
+type CrashOption func(c *CrashFs)
+
+// WithAtomicRename makes Rename durable as soon as it returns, like on file systems that journal the metadata.
+// Without this option, a Rename only survives a crash once both parent directories are synced.
+func WithAtomicRename() CrashOption {
+	return func(c *CrashFs) {
+		c.atomicRename = true
+	}
+}
+
+// CrashFs wraps an afero.Fs and keeps track of what would survive a power loss.
+//
+// The content of a file becomes durable when the file is synced. A directory entry, for example a created, removed or
+// renamed file, becomes durable when its parent directory is synced. Everything that exists in the wrapped afero.Fs
+// when CrashFs is created is durable. Because CrashFs reads the whole wrapped afero.Fs when it is created, it is meant
+// to wrap an in-memory afero.Fs or an afero.BasePathFs.
+type CrashFs struct {
+	FsCallbacks
+
+	base         afero.Fs
+	atomicRename bool
+
+	mu      sync.Mutex
+	nextID  int
+	entries map[string]int
+	durable map[string]int
+	inodes  map[int]*crashInode
+}
+
+type crashInode struct {
+	isDir bool
+	mode  fs.FileMode
+	data  []byte
+}
+
+// NewCrashFs creates a new CrashFs.
+func NewCrashFs(fs afero.Fs, opts ...CrashOption) *CrashFs { //nolint: funlen
+	c := &CrashFs{
+		base:    fs,
+		entries: make(map[string]int),
+		durable: make(map[string]int),
+		inodes:  make(map[int]*crashInode),
+	}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	c.load()
+
+	c.FsCallbacks = OverrideFs(fs, FsCallbacks{
+		CreateFunc: func(name string) (afero.File, error) {
+			f, err := fs.Create(name)
+			if err != nil {
+				return nil, err
+			}
+
+			return c.file(c.add(name, false), f), nil
+		},
+		MkdirFunc: func(name string, perm os.FileMode) error {
+			if err := fs.Mkdir(name, perm); err != nil {
+				return err
+			}
+
+			c.add(name, true)
+
+			return nil
+		},
+		MkdirAllFunc: func(path string, perm os.FileMode) error {
+			if err := fs.MkdirAll(path, perm); err != nil {
+				return err
+			}
+
+			for p := filepath.Clean(path); !isRoot(p); p = filepath.Dir(p) {
+				c.add(p, true)
+			}
+
+			return nil
+		},
+		OpenFunc: func(name string) (afero.File, error) {
+			f, err := fs.Open(name)
+			if err != nil {
+				return nil, err
+			}
+
+			return c.file(c.add(name, false), f), nil
+		},
+		OpenFileFunc: func(name string, flag int, perm os.FileMode) (afero.File, error) {
+			f, err := fs.OpenFile(name, flag, perm)
+			if err != nil {
+				return nil, err
+			}
+
+			return c.file(c.add(name, false), f), nil
+		},
+		RemoveFunc: func(name string) error {
+			if err := fs.Remove(name); err != nil {
+				return err
+			}
+
+			c.remove(name)
+
+			return nil
+		},
+		RemoveAllFunc: func(path string) error {
+			if err := fs.RemoveAll(path); err != nil {
+				return err
+			}
+
+			c.remove(path)
+
+			return nil
+		},
+		RenameFunc: func(oldname, newname string) error {
+			if err := fs.Rename(oldname, newname); err != nil {
+				return err
+			}
+
+			c.rename(oldname, newname)
+
+			return nil
+		},
+	})
+
+	return c
+}
+
+// load marks everything in the wrapped afero.Fs as durable.
+func (c *CrashFs) load() {
+	_ = afero.Walk(c.base, string(filepath.Separator), func(path string, fi fs.FileInfo, err error) error { //nolint: errcheck
+		if err != nil || isRoot(path) {
+			return nil //nolint: nilerr
+		}
+
+		ino := &crashInode{isDir: fi.IsDir(), mode: fi.Mode()}
+
+		if !fi.IsDir() {
+			ino.data, _ = afero.ReadFile(c.base, path) //nolint: errcheck
+		}
+
+		c.nextID++
+		c.inodes[c.nextID] = ino
+		c.entries[path] = c.nextID
+		c.durable[path] = c.nextID
+
+		return nil
+	})
+}
+
+// add registers the file or directory if it is not known yet and returns its inode.
+func (c *CrashFs) add(name string, isDir bool) int {
+	name = filepath.Clean(name)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if id, ok := c.entries[name]; ok {
+		return id
+	}
+
+	mode := fs.FileMode(0o644)
+
+	if fi, err := c.base.Stat(name); err == nil {
+		isDir = fi.IsDir()
+		mode = fi.Mode()
+	}
+
+	c.nextID++
+	c.inodes[c.nextID] = &crashInode{isDir: isDir, mode: mode}
+	c.entries[name] = c.nextID
+
+	return c.nextID
+}
+
+func (c *CrashFs) remove(path string) {
+	path = filepath.Clean(path)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name := range c.entries {
+		if isSubPath(path, name) {
+			delete(c.entries, name)
+		}
+	}
+}
+
+func (c *CrashFs) rename(oldname, newname string) {
+	oldname = filepath.Clean(oldname)
+	newname = filepath.Clean(newname)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	moveEntries(c.entries, oldname, newname)
+
+	if c.atomicRename {
+		moveEntries(c.durable, oldname, newname)
+	}
+}
+
+func moveEntries(entries map[string]int, oldname, newname string) {
+	moved := make(map[string]int)
+
+	for name, id := range entries {
+		if isSubPath(oldname, name) {
+			moved[newname+strings.TrimPrefix(name, oldname)] = id
+
+			delete(entries, name)
+		}
+	}
+
+	for name := range entries {
+		if isSubPath(newname, name) {
+			delete(entries, name)
+		}
+	}
+
+	for name, id := range moved {
+		entries[name] = id
+	}
+}
+
+// sync makes the content of a file or the entries of a directory durable.
+func (c *CrashFs) sync(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ino := c.inodes[id]
+
+	for name, entryID := range c.entries {
+		if entryID != id {
+			continue
+		}
+
+		if !ino.isDir {
+			ino.data, _ = afero.ReadFile(c.base, name) //nolint: errcheck
+
+			return
+		}
+
+		for p := range c.durable {
+			if filepath.Dir(p) == name {
+				delete(c.durable, p)
+			}
+		}
+
+		for p, childID := range c.entries {
+			if filepath.Dir(p) == name && p != name {
+				c.durable[p] = childID
+
+				if fi, err := c.base.Stat(p); err == nil {
+					c.inodes[childID].mode = fi.Mode()
+				}
+			}
+		}
+
+		return
+	}
+}
+
+func (c *CrashFs) file(id int, f afero.File) FileCallbacks {
+	return OverrideFile(f, FileCallbacks{
+		SyncFunc: func() error {
+			if err := f.Sync(); err != nil {
+				return err
+			}
+
+			c.sync(id)
+
+			return nil
+		},
+	})
+}
+
+// Crash returns a new afero.Fs with only the durable content, as if the machine lost power. CrashFs is not changed and
+// can still be used, or crashed again.
+func (c *CrashFs) Crash() afero.Fs {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names := make([]string, 0, len(c.durable))
+
+	for name := range c.durable {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	result := afero.NewMemMapFs()
+
+	for _, name := range names {
+		if !c.reachable(name) {
+			continue
+		}
+
+		ino := c.inodes[c.durable[name]]
+
+		if ino.isDir {
+			_ = result.MkdirAll(name, ino.mode.Perm()) //nolint: errcheck
+
+			continue
+		}
+
+		_ = afero.WriteFile(result, name, ino.data, ino.mode.Perm()) //nolint: errcheck
+	}
+
+	return result
+}
+
+// reachable reports whether all the parent directories of the entry are durable.
+func (c *CrashFs) reachable(name string) bool {
+	for p := filepath.Dir(name); !isRoot(p); p = filepath.Dir(p) {
+		id, ok := c.durable[p]
+		if !ok || !c.inodes[id].isDir {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isRoot(path string) bool {
+	return path == "." || filepath.Dir(path) == path
+}
@@ -0,0 +1,248 @@
+package aferomock_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestCrashFs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		opts     []aferomock.CrashOption
+		run      func(t *testing.T, fs afero.Fs)
+		expected map[string]string
+	}{
+		{
+			scenario: "nothing changed",
+			run:      func(*testing.T, afero.Fs) {},
+			expected: map[string]string{
+				"/data/config.yaml": "old",
+			},
+		},
+		{
+			scenario: "write without sync",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				require.NoError(t, afero.WriteFile(fs, "/data/config.yaml", []byte("new"), 0o644))
+			},
+			expected: map[string]string{
+				"/data/config.yaml": "old",
+			},
+		},
+		{
+			scenario: "write with sync",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				f, err := fs.OpenFile("/data/config.yaml", os.O_WRONLY|os.O_TRUNC, 0o644)
+				require.NoError(t, err)
+
+				_, err = f.WriteString("new")
+				require.NoError(t, err)
+
+				require.NoError(t, f.Sync())
+				require.NoError(t, f.Close())
+			},
+			expected: map[string]string{
+				"/data/config.yaml": "new",
+			},
+		},
+		{
+			scenario: "new file synced but not its directory",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				writeSynced(t, fs, "/data/new.txt", "new")
+			},
+			expected: map[string]string{
+				"/data/config.yaml": "old",
+			},
+		},
+		{
+			scenario: "new file and directory synced",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				writeSynced(t, fs, "/data/new.txt", "new")
+				syncDir(t, fs, "/data")
+			},
+			expected: map[string]string{
+				"/data/config.yaml": "old",
+				"/data/new.txt":     "new",
+			},
+		},
+		{
+			scenario: "new file in a new directory that is not durable",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				require.NoError(t, fs.MkdirAll("/data/logs", os.ModePerm))
+				writeSynced(t, fs, "/data/logs/app.log", "log")
+				syncDir(t, fs, "/data/logs")
+			},
+			expected: map[string]string{
+				"/data/config.yaml": "old",
+			},
+		},
+		{
+			scenario: "directory synced but not the file",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				require.NoError(t, afero.WriteFile(fs, "/data/new.txt", []byte("new"), 0o644))
+				syncDir(t, fs, "/data")
+			},
+			expected: map[string]string{
+				"/data/config.yaml": "old",
+				"/data/new.txt":     "",
+			},
+		},
+		{
+			scenario: "remove without directory sync",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				require.NoError(t, fs.Remove("/data/config.yaml"))
+			},
+			expected: map[string]string{
+				"/data/config.yaml": "old",
+			},
+		},
+		{
+			scenario: "remove with directory sync",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				require.NoError(t, fs.Remove("/data/config.yaml"))
+				syncDir(t, fs, "/data")
+			},
+			expected: map[string]string{},
+		},
+		{
+			scenario: "atomic write without directory sync",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				writeSynced(t, fs, "/data/config.yaml.tmp", "new")
+				require.NoError(t, fs.Rename("/data/config.yaml.tmp", "/data/config.yaml"))
+			},
+			expected: map[string]string{
+				"/data/config.yaml": "old",
+			},
+		},
+		{
+			scenario: "atomic write with directory sync",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				writeSynced(t, fs, "/data/config.yaml.tmp", "new")
+				require.NoError(t, fs.Rename("/data/config.yaml.tmp", "/data/config.yaml"))
+				syncDir(t, fs, "/data")
+			},
+			expected: map[string]string{
+				"/data/config.yaml": "new",
+			},
+		},
+		{
+			scenario: "atomic rename of a durable file",
+			opts:     []aferomock.CrashOption{aferomock.WithAtomicRename()},
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				require.NoError(t, fs.Rename("/data/config.yaml", "/data/config.yaml.bak"))
+			},
+			expected: map[string]string{
+				"/data/config.yaml.bak": "old",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			base := afero.NewMemMapFs()
+
+			require.NoError(t, afero.WriteFile(base, "/data/config.yaml", []byte("old"), 0o644))
+
+			fs := aferomock.NewCrashFs(base, tc.opts...)
+
+			tc.run(t, fs)
+
+			assert.Equal(t, tc.expected, readTree(t, fs.Crash()))
+		})
+	}
+}
+
+func TestCrashFs_CrashTwice(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewCrashFs(afero.NewMemMapFs())
+
+	writeSynced(t, fs, "/file.txt", "hello")
+	syncDir(t, fs, "/")
+
+	first := fs.Crash()
+
+	require.NoError(t, afero.WriteFile(first, "/file.txt", []byte("changed"), 0o644))
+
+	assert.Equal(t, map[string]string{"/file.txt": "hello"}, readTree(t, fs.Crash()))
+}
+
+func writeSynced(t *testing.T, fs afero.Fs, name, content string) {
+	t.Helper()
+
+	f, err := fs.Create(name)
+	require.NoError(t, err)
+
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+
+	require.NoError(t, f.Sync())
+	require.NoError(t, f.Close())
+}
+
+func syncDir(t *testing.T, fs afero.Fs, name string) {
+	t.Helper()
+
+	d, err := fs.Open(name)
+	require.NoError(t, err)
+
+	require.NoError(t, d.Sync())
+	require.NoError(t, d.Close())
+}
+
+// readTree returns the content of all the files in the fs.
+func readTree(t *testing.T, fs afero.Fs) map[string]string {
+	t.Helper()
+
+	result := make(map[string]string)
+
+	err := afero.Walk(fs, "/", func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+
+		content, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+
+		result[path] = string(content)
+
+		return nil
+	})
+
+	require.NoError(t, err)
+
+	return result
+}
@@ -0,0 +1,111 @@
+package aferomock
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// The POSIX error numbers of the catalogue, with their Windows equivalents.
+//
+//	fs.On("Rename", "/mnt/a/file.txt", "/mnt/b/file.txt").
+//		Return(aferomock.EXDEV.Link("rename", "/mnt/a/file.txt", "/mnt/b/file.txt"))
+//
+//	fs.On("Open", "file.txt").
+//		Return(nil, aferomock.EMFILE.Windows().Path("open", "file.txt"))
+var (
+	// EACCES is the error when the permission is denied, it is fs.ErrPermission.
+	EACCES = Errno{
+		Name: "EACCES", Errno: syscall.EACCES,
+		WindowsCode: 5, WindowsName: "ERROR_ACCESS_DENIED", WindowsMessage: "Access is denied.",
+	}
+	// EROFS is the error when the file system is read-only.
+	EROFS = Errno{
+		Name: "EROFS", Errno: syscall.EROFS,
+		WindowsCode: 19, WindowsName: "ERROR_WRITE_PROTECT", WindowsMessage: "The media is write protected.",
+	}
+	// EMFILE is the error when the process has too many open files.
+	EMFILE = Errno{
+		Name: "EMFILE", Errno: syscall.EMFILE,
+		WindowsCode: 4, WindowsName: "ERROR_TOO_MANY_OPEN_FILES", WindowsMessage: "The system cannot open the file.",
+	}
+	// EXDEV is the error when a file is renamed or linked across devices.
+	EXDEV = Errno{
+		Name: "EXDEV", Errno: syscall.EXDEV,
+		WindowsCode: 17, WindowsName: "ERROR_NOT_SAME_DEVICE",
+		WindowsMessage: "The system cannot move the file to a different disk drive.",
+	}
+	// EBUSY is the error when the file or the device is in use.
+	EBUSY = Errno{
+		Name: "EBUSY", Errno: syscall.EBUSY,
+		WindowsCode: 32, WindowsName: "ERROR_SHARING_VIOLATION",
+		WindowsMessage: "The process cannot access the file because it is being used by another process.",
+	}
+	// ENAMETOOLONG is the error when the name of a file is too long.
+	ENAMETOOLONG = Errno{
+		Name: "ENAMETOOLONG", Errno: syscall.ENAMETOOLONG,
+		WindowsCode: 206, WindowsName: "ERROR_FILENAME_EXCED_RANGE",
+		WindowsMessage: "The filename or extension is too long.",
+	}
+)
+
+// Errno is a POSIX error number, with its Windows equivalent, that is wrapped like the errors of the os package by Path,
+// Link and Syscall. The catalogue has EACCES, EROFS, EMFILE, EXDEV, EBUSY and ENAMETOOLONG, other error numbers can be
+// declared with the same fields.
+type Errno struct {
+	// Name is the POSIX name, for example EXDEV.
+	Name string
+	// Errno is the POSIX error number.
+	Errno syscall.Errno
+
+	// WindowsCode is the code of the Windows error, for example 17.
+	WindowsCode uint32
+	// WindowsName is the name of the Windows error, for example ERROR_NOT_SAME_DEVICE.
+	WindowsName string
+	// WindowsMessage is the message of the Windows error.
+	WindowsMessage string
+
+	windows bool
+}
+
+// Windows returns the Errno mapped to its Windows error. On Windows, the error is the syscall.Errno of the Windows
+// code. On the other platforms, the error has the code, the name and the message of the Windows error, and matches the
+// same fs errors as the POSIX error number, so the portability of the code can be tested without Windows.
+func (e Errno) Windows() Errno {
+	e.windows = true
+
+	return e
+}
+
+// Err returns the error number, without the operation.
+func (e Errno) Err() error {
+	if e.windows {
+		return windowsError(e)
+	}
+
+	return e.Errno
+}
+
+// Path returns the error of an operation on a path, a *fs.PathError like the os package.
+func (e Errno) Path(op, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: e.Err()}
+}
+
+// Link returns the error of an operation on two paths, such as rename or link, an *os.LinkError like the os package.
+func (e Errno) Link(op, oldname, newname string) error {
+	return &os.LinkError{Op: op, Old: oldname, New: newname, Err: e.Err()}
+}
+
+// Syscall returns the error of a system call that is not about a path, an *os.SyscallError like the os package.
+func (e Errno) Syscall(name string) error {
+	return os.NewSyscallError(name, e.Err())
+}
+
+// String returns the name of the error number, the Windows name when it is mapped to Windows.
+func (e Errno) String() string {
+	if e.windows {
+		return e.WindowsName
+	}
+
+	return e.Name
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package aferomock
+
+import "syscall"
+
+// windowsError returns the Windows error of the error number, as a windowsErrno because syscall.Errno has the error
+// numbers of the current platform.
+func windowsError(e Errno) error {
+	return &windowsErrno{message: e.WindowsMessage, errno: e.Errno}
+}
+
+// windowsErrno is a Windows error on the other platforms, see Errno.Windows.
+type windowsErrno struct {
+	message string
+	errno   syscall.Errno
+}
+
+// Error returns the message of the Windows error.
+func (e *windowsErrno) Error() string {
+	return e.message
+}
+
+// Is reports whether the error matches the target like the POSIX error number, for example fs.ErrPermission.
+func (e *windowsErrno) Is(target error) bool {
+	return e.errno.Is(target)
+}
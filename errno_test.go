@@ -0,0 +1,94 @@
+package aferomock_test
+
+import (
+	"io/fs"
+	"os"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestErrno(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		errno    aferomock.Errno
+		expected syscall.Errno
+	}{
+		{errno: aferomock.EACCES, expected: syscall.EACCES},
+		{errno: aferomock.EROFS, expected: syscall.EROFS},
+		{errno: aferomock.EMFILE, expected: syscall.EMFILE},
+		{errno: aferomock.EXDEV, expected: syscall.EXDEV},
+		{errno: aferomock.EBUSY, expected: syscall.EBUSY},
+		{errno: aferomock.ENAMETOOLONG, expected: syscall.ENAMETOOLONG},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.errno.String(), func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, tc.errno.Err())
+
+			err := tc.errno.Path("open", "a.txt")
+
+			assert.Equal(t, &fs.PathError{Op: "open", Path: "a.txt", Err: tc.expected}, err)
+			require.ErrorIs(t, err, tc.expected)
+
+			err = tc.errno.Link("rename", "a.txt", "b.txt")
+
+			assert.Equal(t, &os.LinkError{Op: "rename", Old: "a.txt", New: "b.txt", Err: tc.expected}, err)
+			require.ErrorIs(t, err, tc.expected)
+
+			err = tc.errno.Syscall("pipe")
+
+			assert.Equal(t, &os.SyscallError{Syscall: "pipe", Err: tc.expected}, err)
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
+
+func TestErrno_Windows(t *testing.T) {
+	t.Parallel()
+
+	errno := aferomock.EACCES.Windows()
+
+	assert.Equal(t, "ERROR_ACCESS_DENIED", errno.String())
+	assert.Equal(t, "EACCES", aferomock.EACCES.String())
+
+	err := errno.Path("open", "a.txt")
+
+	assert.Equal(t, "open a.txt: Access is denied.", err.Error())
+	require.ErrorIs(t, err, fs.ErrPermission)
+
+	if runtime.GOOS != "windows" {
+		assert.NotErrorIs(t, err, syscall.EACCES)
+	}
+
+	err = aferomock.EXDEV.Windows().Link("rename", "a.txt", "b.txt")
+
+	assert.Equal(t, "rename a.txt b.txt: The system cannot move the file to a different disk drive.", err.Error())
+	assert.NotErrorIs(t, err, syscall.EXDEV)
+}
+
+func TestErrno_RenameAcrossDevices(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		aferomock.CheckBareErrors(t, fs)
+
+		fs.On("Rename", "/mnt/a/file.txt", "/mnt/b/file.txt").
+			Return(aferomock.EXDEV.Link("rename", "/mnt/a/file.txt", "/mnt/b/file.txt"))
+	})(t)
+
+	err := fs.Rename("/mnt/a/file.txt", "/mnt/b/file.txt")
+
+	var linkErr *os.LinkError
+
+	require.ErrorAs(t, err, &linkErr)
+	require.ErrorIs(t, err, syscall.EXDEV)
+}
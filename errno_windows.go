@@ -0,0 +1,10 @@
+//go:build windows
+
+package aferomock
+
+import "syscall"
+
+// windowsError returns the Windows error of the error number.
+func windowsError(e Errno) error {
+	return syscall.Errno(e.WindowsCode)
+}
@@ -0,0 +1,117 @@
+package aferomock
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"syscall"
+	"testing"
+)
+
+// ErrNotExist returns the error of the os package when the path does not exist: a *fs.PathError with syscall.ENOENT,
+// which is fs.ErrNotExist.
+//
+//	fs.On("Open", "config.yaml").Return(nil, aferomock.ErrNotExist("open", "config.yaml"))
+func ErrNotExist(op, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: syscall.ENOENT}
+}
+
+// ErrExist returns the error of the os package when the path already exists: a *fs.PathError with syscall.EEXIST,
+// which is fs.ErrExist.
+func ErrExist(op, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: syscall.EEXIST}
+}
+
+// ErrPermission returns the error of the os package when the permission is denied: a *fs.PathError with
+// syscall.EACCES, which is fs.ErrPermission.
+func ErrPermission(op, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: syscall.EACCES}
+}
+
+// ErrLinkExists returns the error of the os package when the new path of a link or a rename already exists: an
+// *os.LinkError with syscall.EEXIST, which is fs.ErrExist.
+func ErrLinkExists(op, oldname, newname string) error {
+	return &os.LinkError{Op: op, Old: oldname, New: newname, Err: syscall.EEXIST}
+}
+
+// ErrIsDir returns the error of the os package when the path is a directory: a *fs.PathError with syscall.EISDIR.
+func ErrIsDir(op, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: syscall.EISDIR}
+}
+
+// ErrNotDir returns the error of the os package when an element of the path is not a directory: a *fs.PathError with
+// syscall.ENOTDIR.
+func ErrNotDir(op, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: syscall.ENOTDIR}
+}
+
+// ErrNotEmpty returns the error of the os package when the directory is not empty: a *fs.PathError with
+// syscall.ENOTEMPTY.
+func ErrNotEmpty(op, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: syscall.ENOTEMPTY}
+}
+
+// CheckBareErrors fails the test at its end when an expectation of a path operation of the Fs mock, all the methods
+// but Name, returns an error that is not a *fs.PathError, an *os.LinkError or an *os.SyscallError, such as
+// errors.New("open error"). Unlike the errors of the os package, a bare error does not carry the operation and the
+// path, and is not matched by errors.As, so the code under test may behave differently than in production. The errors
+// returned by a function are not checked.
+//
+//	fs := aferomock.NewFs(t)
+//
+//	aferomock.CheckBareErrors(t, fs)
+//
+//	fs.On("Open", "config.yaml").Return(nil, aferomock.ErrNotExist("open", "config.yaml"))
+func CheckBareErrors(tb testing.TB, fs *Fs) {
+	tb.Helper()
+
+	tb.Cleanup(func() {
+		tb.Helper()
+
+		for _, msg := range bareErrors(fs) {
+			tb.Errorf("%s", msg)
+		}
+	})
+}
+
+// bareErrors returns the messages of the expectations that return a bare error. It is called at the end of the test,
+// the expectations are read under the lock of the mock because a goroutine of the test may still call it.
+func bareErrors(fs *Fs) []string {
+	mu := mockMutex(&fs.Mock)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var msgs []string
+
+	for _, c := range fs.ExpectedCalls {
+		if c.Method == "Name" || len(c.ReturnArguments) == 0 {
+			continue
+		}
+
+		err, ok := c.ReturnArguments[len(c.ReturnArguments)-1].(error)
+		if !ok || err == nil || !isBareError(err) {
+			continue
+		}
+
+		msgs = append(msgs, fmt.Sprintf("aferomock: %s returns the bare error %q, "+
+			"use a *fs.PathError or an *os.LinkError like the os package, for example aferomock.ErrNotExist",
+			callString(c.Method, c.Arguments...), err.Error()))
+	}
+
+	sort.Strings(msgs)
+
+	return msgs
+}
+
+func isBareError(err error) bool {
+	var (
+		pathErr    *fs.PathError
+		linkErr    *os.LinkError
+		syscallErr *os.SyscallError
+	)
+
+	return !errors.As(err, &pathErr) && !errors.As(err, &linkErr) && !errors.As(err, &syscallErr)
+}
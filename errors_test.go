@@ -0,0 +1,144 @@
+package aferomock_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestErrors_LikeOs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.txt")
+	file := filepath.Join(dir, "file.txt")
+
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+
+	testCases := []struct {
+		scenario string
+		actual   func() error
+		expected error
+		sentinel error
+	}{
+		{
+			scenario: "not exist",
+			actual: func() error {
+				_, err := os.Open(missing) //nolint: gosec
+
+				return err
+			},
+			expected: aferomock.ErrNotExist("open", missing),
+			sentinel: fs.ErrNotExist,
+		},
+		{
+			scenario: "exist",
+			actual: func() error {
+				return os.Mkdir(dir, os.ModePerm)
+			},
+			expected: aferomock.ErrExist("mkdir", dir),
+			sentinel: fs.ErrExist,
+		},
+		{
+			scenario: "link exists",
+			actual: func() error {
+				return os.Link(file, file)
+			},
+			expected: aferomock.ErrLinkExists("link", file, file),
+			sentinel: fs.ErrExist,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			actual := tc.actual()
+
+			require.ErrorIs(t, actual, tc.sentinel)
+			require.ErrorIs(t, tc.expected, tc.sentinel)
+
+			assert.IsType(t, actual, tc.expected)
+			assert.Equal(t, actual.Error(), tc.expected.Error())
+		})
+	}
+}
+
+func TestErrors_Shape(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		err           error
+		expectedErrno syscall.Errno
+		expectedIs    error
+	}{
+		{scenario: "not exist", err: aferomock.ErrNotExist("open", "a.txt"), expectedErrno: syscall.ENOENT, expectedIs: fs.ErrNotExist},
+		{scenario: "exist", err: aferomock.ErrExist("mkdir", "a.txt"), expectedErrno: syscall.EEXIST, expectedIs: fs.ErrExist},
+		{scenario: "permission", err: aferomock.ErrPermission("mkdir", "a.txt"), expectedErrno: syscall.EACCES, expectedIs: fs.ErrPermission},
+		{scenario: "is dir", err: aferomock.ErrIsDir("open", "a.txt"), expectedErrno: syscall.EISDIR},
+		{scenario: "not dir", err: aferomock.ErrNotDir("open", "a.txt"), expectedErrno: syscall.ENOTDIR},
+		{scenario: "not empty", err: aferomock.ErrNotEmpty("remove", "a.txt"), expectedErrno: syscall.ENOTEMPTY},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			var pathErr *fs.PathError
+
+			require.ErrorAs(t, tc.err, &pathErr)
+			assert.Equal(t, "a.txt", pathErr.Path)
+			assert.Equal(t, tc.expectedErrno, pathErr.Err)
+
+			if tc.expectedIs != nil {
+				assert.ErrorIs(t, tc.err, tc.expectedIs)
+			}
+		})
+	}
+
+	var linkErr *os.LinkError
+
+	require.ErrorAs(t, aferomock.ErrLinkExists("rename", "a.txt", "b.txt"), &linkErr)
+	assert.Equal(t, &os.LinkError{Op: "rename", Old: "a.txt", New: "b.txt", Err: syscall.EEXIST}, linkErr)
+}
+
+func TestCheckBareErrors(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+
+	fs := aferomock.NewFs(ft)
+
+	aferomock.CheckBareErrors(ft, fs)
+
+	fs.On("Open", "a.txt").Return(nil, errors.New("open error")).Maybe()
+	fs.On("Open", "b.txt").Return(nil, aferomock.ErrNotExist("open", "b.txt")).Maybe()
+	fs.On("Rename", "a.txt", "b.txt").Return(aferomock.ErrLinkExists("rename", "a.txt", "b.txt")).Maybe()
+	fs.On("Remove", "a.txt").Return(errors.New("remove error")).Maybe()
+	fs.On("Stat", "a.txt").Return(nil, &os.SyscallError{Syscall: "stat", Err: syscall.EIO}).Maybe()
+	fs.On("Mkdir", "a", os.ModePerm).Return(nil).Maybe()
+	fs.On("Chmod", "a.txt", os.ModePerm).Return(func(string, os.FileMode) error {
+		return errors.New("chmod error")
+	}).Maybe()
+
+	_, err := fs.Open("a.txt")
+	require.Error(t, err)
+
+	ft.RunCleanup()
+
+	expected := []string{
+		`aferomock: Open("a.txt") returns the bare error "open error", use a *fs.PathError or an *os.LinkError like the os package, for example aferomock.ErrNotExist`,
+		`aferomock: Remove("a.txt") returns the bare error "remove error", use a *fs.PathError or an *os.LinkError like the os package, for example aferomock.ErrNotExist`,
+	}
+
+	assert.Equal(t, expected, ft.Errors())
+}
@@ -0,0 +1,199 @@
+package aferomock
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// testDataGroups is the key of the expectation groups of a mock in its test data.
+const testDataGroups = "aferomockGroups"
+
+// ExpectationGroup is a named group of expectations of a mock, it is used to verify a phase of a test without waiting
+// for the cleanup of the mock.
+type ExpectationGroup struct {
+	name string
+	mock *mock.Mock
+
+	mu    sync.Mutex
+	calls []*mock.Call
+	since int
+}
+
+// On adds an expectation to the mock and to the group. See mock.Mock.On.
+func (g *ExpectationGroup) On(methodName string, arguments ...interface{}) *mock.Call {
+	c := g.mock.On(methodName, arguments...)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.calls = append(g.calls, c)
+
+	return c
+}
+
+// AssertSatisfied asserts that all the expectations of the group are met by the calls that happened since the group was
+// created or reset.
+func (g *ExpectationGroup) AssertSatisfied(t mock.TestingT) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// The calls and the expectations are shared with the mock, they are read with its lock held.
+	mu := mockMutex(g.mock)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	m := &mock.Mock{
+		ExpectedCalls: g.calls,
+		Calls:         append([]mock.Call(nil), g.mock.Calls[min(g.since, len(g.mock.Calls)):]...),
+	}
+
+	return m.AssertExpectations(groupT{TestingT: t, name: g.name})
+}
+
+// reset removes the expectations of the group from the mock.
+func (g *ExpectationGroup) reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	mu := mockMutex(g.mock)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := make([]*mock.Call, 0, len(g.mock.ExpectedCalls))
+
+	for _, c := range g.mock.ExpectedCalls {
+		if !containsCall(g.calls, c) {
+			expected = append(expected, c)
+		}
+	}
+
+	g.mock.ExpectedCalls = expected
+	g.calls = nil
+	g.since = len(g.mock.Calls)
+}
+
+func containsCall(calls []*mock.Call, c *mock.Call) bool {
+	for _, call := range calls {
+		if call == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+// groupT prefixes the errors with the name of the group.
+type groupT struct {
+	mock.TestingT
+
+	name string
+}
+
+func (t groupT) Errorf(format string, args ...interface{}) {
+	if h, ok := t.TestingT.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	t.TestingT.Errorf("expectation group %q: "+format, append([]interface{}{t.name}, args...)...)
+}
+
+// mockMutex returns the lock of the mock, testify holds it while it records a call or updates its expectations.
+func mockMutex(m *mock.Mock) *sync.Mutex {
+	f := reflect.ValueOf(m).Elem().FieldByName("mutex")
+
+	return (*sync.Mutex)(unsafe.Pointer(f.UnsafeAddr())) //nolint: gosec
+}
+
+// groupsMu guards the groups in the test data of the mocks.
+var groupsMu sync.Mutex
+
+// groups returns the expectation groups of the mock. They are kept in its test data, so that they are released with
+// the mock.
+func groups(m *mock.Mock) map[string]*ExpectationGroup {
+	data := m.TestData()
+
+	groups, ok := data.Get(testDataGroups).Data().(map[string]*ExpectationGroup)
+	if !ok {
+		groups = make(map[string]*ExpectationGroup)
+
+		data.Set(testDataGroups, groups)
+	}
+
+	return groups
+}
+
+func group(m *mock.Mock, name string) *ExpectationGroup {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+
+	groups := groups(m)
+
+	g, ok := groups[name]
+	if !ok {
+		mu := mockMutex(m)
+
+		mu.Lock()
+		g = &ExpectationGroup{name: name, mock: m, since: len(m.Calls)}
+		mu.Unlock()
+
+		groups[name] = g
+	}
+
+	return g
+}
+
+func resetGroup(m *mock.Mock, name string) {
+	groupsMu.Lock()
+	g, ok := groups(m)[name]
+	groupsMu.Unlock()
+
+	if ok {
+		g.reset()
+	}
+}
+
+// Group returns the expectation group with the given name, the group is created if it does not exist.
+//
+//	g := fs.Group("init")
+//	g.On("MkdirAll", "data", os.ModePerm).Return(nil)
+//
+//	// ...
+//
+//	g.AssertSatisfied(t)
+func (fs *Fs) Group(name string) *ExpectationGroup {
+	return group(&fs.Mock, name)
+}
+
+// ResetGroup removes the expectations of the group from the mock, so that the next phase of the test can start over.
+func (fs *Fs) ResetGroup(name string) {
+	resetGroup(&fs.Mock, name)
+}
+
+// Group returns the expectation group with the given name, the group is created if it does not exist.
+func (f *File) Group(name string) *ExpectationGroup {
+	return group(&f.Mock, name)
+}
+
+// ResetGroup removes the expectations of the group from the mock, so that the next phase of the test can start over.
+func (f *File) ResetGroup(name string) {
+	resetGroup(&f.Mock, name)
+}
+
+// Group returns the expectation group with the given name, the group is created if it does not exist.
+func (fi *FileInfo) Group(name string) *ExpectationGroup {
+	return group(&fi.Mock, name)
+}
+
+// ResetGroup removes the expectations of the group from the mock, so that the next phase of the test can start over.
+func (fi *FileInfo) ResetGroup(name string) {
+	resetGroup(&fi.Mock, name)
+}
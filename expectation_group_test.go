@@ -0,0 +1,158 @@
+package aferomock_test
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestFs_Group(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NopFs(t)
+
+	g := fs.Group("init")
+
+	g.On("MkdirAll", "data", os.ModePerm).Return(nil)
+	g.On("Stat", "data/config.yaml").Return(nil, os.ErrNotExist).Once()
+
+	assert.Same(t, g, fs.Group("init"))
+
+	require.NoError(t, fs.MkdirAll("data", os.ModePerm))
+
+	_, err := fs.Stat("data/config.yaml")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	assert.True(t, g.AssertSatisfied(t))
+
+	fs.ResetGroup("init")
+
+	// The expectations of the group are removed, the next phase can expect the same calls.
+	g = fs.Group("init")
+	g.On("Stat", "data/config.yaml").Return(nil, os.ErrPermission).Once()
+
+	_, err = fs.Stat("data/config.yaml")
+	require.ErrorIs(t, err, os.ErrPermission)
+
+	assert.True(t, g.AssertSatisfied(t))
+}
+
+func TestFs_Group_NotSatisfied(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+	fs := aferomock.NewFs(ft)
+
+	g := fs.Group("init")
+
+	g.On("MkdirAll", "data", os.ModePerm).Return(nil)
+	g.On("Remove", "data/lock").Return(nil)
+	g.On("Name").Return("fs").Maybe()
+
+	require.NoError(t, fs.MkdirAll("data", os.ModePerm))
+
+	assert.False(t, g.AssertSatisfied(ft))
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], `expectation group "init": `)
+	assert.Contains(t, errs[0], "FAIL: 2 out of 3 expectation(s) were met.")
+
+	fs.ResetGroup("init")
+	ft.RunCleanup()
+
+	// The reset expectations are not checked at cleanup.
+	assert.Len(t, ft.Errors(), 1)
+}
+
+func TestFs_Group_CallsBeforeGroupAreIgnored(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+	fs := aferomock.NewFs(ft)
+
+	fs.On("Remove", "data/lock").Return(nil)
+
+	require.NoError(t, fs.Remove("data/lock"))
+
+	g := fs.Group("cleanup")
+
+	g.On("Remove", "data/lock").Return(nil)
+
+	assert.False(t, g.AssertSatisfied(ft))
+}
+
+func TestFs_Group_ConcurrentCalls(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NopFs(t)
+
+	fs.On("Stat", "data").Return(nil, os.ErrNotExist)
+
+	var wg sync.WaitGroup
+
+	for range 4 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range 100 {
+				_, _ = fs.Stat("data") //nolint: errcheck
+			}
+		}()
+	}
+
+	for range 100 {
+		g := fs.Group("phase")
+
+		g.On("Remove", "data").Return(nil).Maybe()
+
+		assert.True(t, g.AssertSatisfied(t))
+
+		fs.ResetGroup("phase")
+	}
+
+	wg.Wait()
+}
+
+func TestFile_Group(t *testing.T) {
+	t.Parallel()
+
+	f := aferomock.NopFile(t)
+
+	g := f.Group("write")
+
+	g.On("WriteString", "hello").Return(5, nil).Once()
+	g.On("Close").Return(nil).Once()
+
+	_, err := f.WriteString("hello")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	assert.True(t, g.AssertSatisfied(t))
+
+	f.ResetGroup("write")
+}
+
+func TestFileInfo_Group(t *testing.T) {
+	t.Parallel()
+
+	fi := aferomock.NopFileInfo(t)
+
+	g := fi.Group("stat")
+
+	g.On("Size").Return(int64(10))
+
+	assert.Equal(t, int64(10), fi.Size())
+	assert.True(t, g.AssertSatisfied(t))
+
+	fi.ResetGroup("stat")
+	fi.ResetGroup("unknown")
+}
@@ -23,71 +23,186 @@ type FileCallbacks struct {
 	WriteFunc        func(p []byte) (int, error)
 	WriteAtFunc      func(p []byte, off int64) (int, error)
 	WriteStringFunc  func(s string) (int, error)
+
+	// Fallback is called when a callback is not set.
+	Fallback afero.File
+	// Unset is called when a callback is not set and there is no Fallback.
+	Unset UnsetFunc
+}
+
+func (f FileCallbacks) unset(method string, args ...interface{}) error {
+	return unset(f.Unset, "FileCallbacks", method, args...)
 }
 
 // Close satisfies the afero.File interface.
 func (f FileCallbacks) Close() error {
-	return f.CloseFunc()
+	switch {
+	case f.CloseFunc != nil:
+		return f.CloseFunc()
+
+	case f.Fallback != nil:
+		return f.Fallback.Close()
+	}
+
+	return f.unset("Close")
 }
 
 // Name satisfies the afero.File interface.
 func (f FileCallbacks) Name() string {
-	return f.NameFunc()
+	switch {
+	case f.NameFunc != nil:
+		return f.NameFunc()
+
+	case f.Fallback != nil:
+		return f.Fallback.Name()
+	}
+
+	_ = f.unset("Name") //nolint: errcheck
+
+	return ""
 }
 
 // Read satisfies the afero.File interface.
 func (f FileCallbacks) Read(p []byte) (int, error) {
-	return f.ReadFunc(p)
+	switch {
+	case f.ReadFunc != nil:
+		return f.ReadFunc(p)
+
+	case f.Fallback != nil:
+		return f.Fallback.Read(p)
+	}
+
+	return 0, f.unset("Read", p)
 }
 
 // ReadAt satisfies the afero.File interface.
 func (f FileCallbacks) ReadAt(p []byte, off int64) (int, error) {
-	return f.ReadAtFunc(p, off)
+	switch {
+	case f.ReadAtFunc != nil:
+		return f.ReadAtFunc(p, off)
+
+	case f.Fallback != nil:
+		return f.Fallback.ReadAt(p, off)
+	}
+
+	return 0, f.unset("ReadAt", p, off)
 }
 
 // Readdir satisfies the afero.File interface.
 func (f FileCallbacks) Readdir(count int) ([]fs.FileInfo, error) {
-	return f.ReaddirFunc(count)
+	switch {
+	case f.ReaddirFunc != nil:
+		return f.ReaddirFunc(count)
+
+	case f.Fallback != nil:
+		return f.Fallback.Readdir(count)
+	}
+
+	return nil, f.unset("Readdir", count)
 }
 
 // Readdirnames satisfies the afero.File interface.
 func (f FileCallbacks) Readdirnames(n int) ([]string, error) {
-	return f.ReaddirnamesFunc(n)
+	switch {
+	case f.ReaddirnamesFunc != nil:
+		return f.ReaddirnamesFunc(n)
+
+	case f.Fallback != nil:
+		return f.Fallback.Readdirnames(n)
+	}
+
+	return nil, f.unset("Readdirnames", n)
 }
 
 // Seek satisfies the afero.File interface.
 func (f FileCallbacks) Seek(offset int64, whence int) (int64, error) {
-	return f.SeekFunc(offset, whence)
+	switch {
+	case f.SeekFunc != nil:
+		return f.SeekFunc(offset, whence)
+
+	case f.Fallback != nil:
+		return f.Fallback.Seek(offset, whence)
+	}
+
+	return 0, f.unset("Seek", offset, whence)
 }
 
 // Stat satisfies the afero.File interface.
 func (f FileCallbacks) Stat() (fs.FileInfo, error) {
-	return f.StatFunc()
+	switch {
+	case f.StatFunc != nil:
+		return f.StatFunc()
+
+	case f.Fallback != nil:
+		return f.Fallback.Stat()
+	}
+
+	return nil, f.unset("Stat")
 }
 
 // Sync satisfies the afero.File interface.
 func (f FileCallbacks) Sync() error {
-	return f.SyncFunc()
+	switch {
+	case f.SyncFunc != nil:
+		return f.SyncFunc()
+
+	case f.Fallback != nil:
+		return f.Fallback.Sync()
+	}
+
+	return f.unset("Sync")
 }
 
 // Truncate satisfies the afero.File interface.
 func (f FileCallbacks) Truncate(size int64) error {
-	return f.TruncateFunc(size)
+	switch {
+	case f.TruncateFunc != nil:
+		return f.TruncateFunc(size)
+
+	case f.Fallback != nil:
+		return f.Fallback.Truncate(size)
+	}
+
+	return f.unset("Truncate", size)
 }
 
 // Write satisfies the afero.File interface.
 func (f FileCallbacks) Write(p []byte) (int, error) {
-	return f.WriteFunc(p)
+	switch {
+	case f.WriteFunc != nil:
+		return f.WriteFunc(p)
+
+	case f.Fallback != nil:
+		return f.Fallback.Write(p)
+	}
+
+	return 0, f.unset("Write", p)
 }
 
 // WriteAt satisfies the afero.File interface.
 func (f FileCallbacks) WriteAt(p []byte, off int64) (int, error) {
-	return f.WriteAtFunc(p, off)
+	switch {
+	case f.WriteAtFunc != nil:
+		return f.WriteAtFunc(p, off)
+
+	case f.Fallback != nil:
+		return f.Fallback.WriteAt(p, off)
+	}
+
+	return 0, f.unset("WriteAt", p, off)
 }
 
 // WriteString satisfies the afero.File interface.
 func (f FileCallbacks) WriteString(s string) (int, error) {
-	return f.WriteStringFunc(s)
+	switch {
+	case f.WriteStringFunc != nil:
+		return f.WriteStringFunc(s)
+
+	case f.Fallback != nil:
+		return f.Fallback.WriteString(s)
+	}
+
+	return 0, f.unset("WriteString", s)
 }
 
 // OverrideFile overrides the afero.File methods with the provided callbacks.
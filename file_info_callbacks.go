@@ -15,36 +15,105 @@ type FileInfoCallbacks struct {
 	ModTimeFunc func() time.Time
 	IsDirFunc   func() bool
 	SysFunc     func() interface{}
+
+	// Fallback is called when a callback is not set.
+	Fallback fs.FileInfo
+	// Unset is called when a callback is not set and there is no Fallback.
+	Unset UnsetFunc
+}
+
+func (f FileInfoCallbacks) unset(method string, args ...interface{}) error {
+	return unset(f.Unset, "FileInfoCallbacks", method, args...)
 }
 
 // Name satisfies the fs.FileInfo interface.
 func (f FileInfoCallbacks) Name() string {
-	return f.NameFunc()
+	switch {
+	case f.NameFunc != nil:
+		return f.NameFunc()
+
+	case f.Fallback != nil:
+		return f.Fallback.Name()
+	}
+
+	_ = f.unset("Name") //nolint: errcheck
+
+	return ""
 }
 
 // Size satisfies the fs.FileInfo interface.
 func (f FileInfoCallbacks) Size() int64 {
-	return f.SizeFunc()
+	switch {
+	case f.SizeFunc != nil:
+		return f.SizeFunc()
+
+	case f.Fallback != nil:
+		return f.Fallback.Size()
+	}
+
+	_ = f.unset("Size") //nolint: errcheck
+
+	return 0
 }
 
 // Mode satisfies the fs.FileInfo interface.
 func (f FileInfoCallbacks) Mode() fs.FileMode {
-	return f.ModeFunc()
+	switch {
+	case f.ModeFunc != nil:
+		return f.ModeFunc()
+
+	case f.Fallback != nil:
+		return f.Fallback.Mode()
+	}
+
+	_ = f.unset("Mode") //nolint: errcheck
+
+	return 0
 }
 
 // ModTime satisfies the fs.FileInfo interface.
 func (f FileInfoCallbacks) ModTime() time.Time {
-	return f.ModTimeFunc()
+	switch {
+	case f.ModTimeFunc != nil:
+		return f.ModTimeFunc()
+
+	case f.Fallback != nil:
+		return f.Fallback.ModTime()
+	}
+
+	_ = f.unset("ModTime") //nolint: errcheck
+
+	return time.Time{}
 }
 
 // IsDir satisfies the fs.FileInfo interface.
 func (f FileInfoCallbacks) IsDir() bool {
-	return f.IsDirFunc()
+	switch {
+	case f.IsDirFunc != nil:
+		return f.IsDirFunc()
+
+	case f.Fallback != nil:
+		return f.Fallback.IsDir()
+	}
+
+	_ = f.unset("IsDir") //nolint: errcheck
+
+	return false
 }
 
 // Sys satisfies the fs.FileInfo interface.
 func (f FileInfoCallbacks) Sys() interface{} {
-	return f.SysFunc()
+	switch {
+	case f.SysFunc != nil:
+		return f.SysFunc()
+
+	case f.Fallback != nil:
+		return f.Fallback.Sys()
+	}
+
+	_ = f.unset("Sys") //nolint: errcheck
+
+	return nil
 }
 
 // OverrideFileInfo overrides the fs.FileInfo methods with the provided callbacks.
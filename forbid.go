@@ -0,0 +1,529 @@
+package aferomock
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/mock"
+)
+
+const writeFlags = os.O_WRONLY | os.O_RDWR | os.O_APPEND | os.O_CREATE | os.O_TRUNC
+
+// fsNumArgs and fileNumArgs are the numbers of arguments of the methods of afero.Fs and afero.File.
+var (
+	fsNumArgs = map[string]int{
+		"Chmod": 2, "Chown": 3, "Chtimes": 3, "Create": 1, "Mkdir": 2, "MkdirAll": 2, "Name": 0, "Open": 1,
+		"OpenFile": 3, "Remove": 1, "RemoveAll": 1, "Rename": 2, "Stat": 1,
+	}
+	fileNumArgs = map[string]int{
+		"Close": 0, "Name": 0, "Read": 1, "ReadAt": 2, "Readdir": 1, "Readdirnames": 1, "Seek": 2, "Stat": 0,
+		"Sync": 0, "Truncate": 1, "Write": 1, "WriteAt": 2, "WriteString": 1,
+	}
+
+	fsWriteOps   = []string{"Chmod", "Chown", "Chtimes", "Create", "Mkdir", "MkdirAll", "Remove", "RemoveAll", "Rename"}
+	fileWriteOps = []string{"Truncate", "Write", "WriteAt", "WriteString"}
+)
+
+// ForbidWrites forbids the operations that modify the file system on the Fs mock and on the File mocks that are
+// returned by its Open, OpenFile and Create expectations: Chmod, Chown, Chtimes, Create, Mkdir, MkdirAll, Remove,
+// RemoveAll, Rename, OpenFile with a flag that allows writing, and Truncate, Write, WriteAt and WriteString on the
+// files.
+//
+// A forbidden operation fails the test with a message naming the operation, its arguments and the stack trace of the
+// call, and then returns a *fs.PathError with fs.ErrPermission. Because the expectations of a mock are matched in
+// order, ForbidWrites has to be called after the other expectations.
+//
+//	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+//		fs.On("Open", "config.yaml").Return(f, nil)
+//
+//		aferomock.ForbidWrites(t, fs)
+//	})(t)
+func ForbidWrites(tb testing.TB, fs *Fs) {
+	tb.Helper()
+
+	for _, op := range fsWriteOps {
+		forbid(&fs.Mock, op, fsNumArgs[op], forbiddenFsReturn(tb, op))
+	}
+
+	fs.On("OpenFile", mock.Anything, mock.MatchedBy(func(flag int) bool {
+		return flag&writeFlags != 0
+	}), mock.Anything).
+		Maybe().
+		Return(forbiddenFsReturn(tb, "OpenFile"))
+
+	for name, f := range returnedFiles(fs) {
+		for _, op := range fileWriteOps {
+			forbid(&f.Mock, op, fileNumArgs[op], forbiddenFileReturn(tb, op, name))
+		}
+	}
+}
+
+// ForbidOps forbids the given operations on the Fs mock. The operations of afero.File, such as Write or Sync, are
+// forbidden on the File mocks that are returned by the Open, OpenFile and Create expectations of the Fs mock.
+//
+// A forbidden operation fails the test and then returns a *fs.PathError with fs.ErrPermission, see ForbidWrites.
+// Because the expectations of a mock are matched in order, ForbidOps has to be called after the other expectations.
+func ForbidOps(tb testing.TB, fs *Fs, ops ...string) {
+	tb.Helper()
+
+	checkOps(tb, ops)
+
+	files := returnedFiles(fs)
+
+	for _, op := range ops {
+		if n, ok := fsNumArgs[op]; ok {
+			forbid(&fs.Mock, op, n, forbiddenFsReturn(tb, op))
+		}
+
+		if n, ok := fileNumArgs[op]; ok {
+			for name, f := range files {
+				forbid(&f.Mock, op, n, forbiddenFileReturn(tb, op, name))
+			}
+		}
+	}
+}
+
+// checkOps reports the operations that are neither a method of afero.Fs nor a method of afero.File.
+func checkOps(tb testing.TB, ops []string) {
+	tb.Helper()
+
+	for _, op := range ops {
+		if _, ok := fsNumArgs[op]; ok {
+			continue
+		}
+
+		if _, ok := fileNumArgs[op]; ok {
+			continue
+		}
+
+		tb.Errorf("aferomock: unknown operation %q", op)
+	}
+}
+
+// forbid expects the operation with any arguments, the expectation returns the function that fails the test.
+func forbid(m *mock.Mock, op string, numArgs int, ret interface{}) {
+	args := make([]interface{}, numArgs)
+
+	for i := range args {
+		args[i] = mock.Anything
+	}
+
+	m.On(op, args...).
+		Maybe().
+		Return(ret)
+}
+
+// forbiddenFsReturn returns the function that the Fs mock calls for a forbidden operation. It fails the test and
+// returns a *fs.PathError with fs.ErrPermission.
+func forbiddenFsReturn(tb testing.TB, op string) interface{} { //nolint: cyclop
+	fail := func(path string, args ...interface{}) error {
+		tb.Errorf("%s\n%s", forbiddenMessage(op, "", args...), callerStack())
+
+		return &fs.PathError{Op: strings.ToLower(op), Path: path, Err: fs.ErrPermission}
+	}
+
+	switch op {
+	case "Chmod", "Mkdir", "MkdirAll":
+		return func(name string, mode fs.FileMode) error {
+			return fail(name, name, mode)
+		}
+
+	case "Chown":
+		return func(name string, uid, gid int) error {
+			return fail(name, name, uid, gid)
+		}
+
+	case "Chtimes":
+		return func(name string, atime, mtime time.Time) error {
+			return fail(name, name, atime, mtime)
+		}
+
+	case "Create", "Open":
+		return func(name string) (afero.File, error) {
+			return nil, fail(name, name)
+		}
+
+	case "Name":
+		return func() string {
+			_ = fail("") //nolint: errcheck
+
+			return ""
+		}
+
+	case "OpenFile":
+		return func(name string, flag int, perm fs.FileMode) (afero.File, error) {
+			return nil, fail(name, name, flag, perm)
+		}
+
+	case "Remove", "RemoveAll":
+		return func(name string) error {
+			return fail(name, name)
+		}
+
+	case "Rename":
+		return func(oldname, newname string) error {
+			return fail(oldname, oldname, newname)
+		}
+
+	case "Stat":
+		return func(name string) (fs.FileInfo, error) {
+			return nil, fail(name, name)
+		}
+	}
+
+	panic("aferomock: unknown operation of afero.Fs: " + op)
+}
+
+// forbiddenFileReturn returns the function that the File mock calls for a forbidden operation. It fails the test and
+// returns a *fs.PathError with fs.ErrPermission.
+func forbiddenFileReturn(tb testing.TB, op, name string) interface{} { //nolint: cyclop
+	fail := func(args ...interface{}) error {
+		tb.Errorf("%s\n%s", forbiddenMessage(op, name, args...), callerStack())
+
+		return &fs.PathError{Op: strings.ToLower(op), Path: name, Err: fs.ErrPermission}
+	}
+
+	switch op {
+	case "Close", "Sync":
+		return func() error {
+			return fail()
+		}
+
+	case "Name":
+		return func() string {
+			_ = fail() //nolint: errcheck
+
+			return ""
+		}
+
+	case "Read", "Write":
+		return func(p []byte) (int, error) {
+			return 0, fail(p)
+		}
+
+	case "ReadAt", "WriteAt":
+		return func(p []byte, off int64) (int, error) {
+			return 0, fail(p, off)
+		}
+
+	case "Readdir":
+		return func(count int) ([]fs.FileInfo, error) {
+			return nil, fail(count)
+		}
+
+	case "Readdirnames":
+		return func(n int) ([]string, error) {
+			return nil, fail(n)
+		}
+
+	case "Seek":
+		return func(offset int64, whence int) (int64, error) {
+			return 0, fail(offset, whence)
+		}
+
+	case "Stat":
+		return func() (fs.FileInfo, error) {
+			return nil, fail()
+		}
+
+	case "Truncate":
+		return func(size int64) error {
+			return fail(size)
+		}
+
+	case "WriteString":
+		return func(s string) (int, error) {
+			return 0, fail(s)
+		}
+	}
+
+	panic("aferomock: unknown operation of afero.File: " + op)
+}
+
+// returnedFiles returns the File mocks returned by the Open, OpenFile and Create expectations, by name.
+func returnedFiles(fs *Fs) map[string]*File {
+	mu := mockMutex(&fs.Mock)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	files := make(map[string]*File)
+
+	for _, c := range fs.ExpectedCalls {
+		if c.Method != "Open" && c.Method != "OpenFile" && c.Method != "Create" {
+			continue
+		}
+
+		if len(c.ReturnArguments) == 0 {
+			continue
+		}
+
+		if f, ok := c.ReturnArguments[0].(*File); ok {
+			files[fmt.Sprint(c.Arguments[0])] = f
+		}
+	}
+
+	return files
+}
+
+func forbiddenMessage(op, file string, args ...interface{}) string {
+	msg := "aferomock: forbidden operation " + callString(op, args...)
+
+	if file != "" {
+		msg += fmt.Sprintf(" on file %q", file)
+	}
+
+	return msg
+}
+
+// callString formats a call of a method with its arguments, for example Remove("data").
+func callString(method string, args ...interface{}) string {
+	formatted := make([]string, len(args))
+
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case string:
+			formatted[i] = fmt.Sprintf("%q", arg)
+
+		case []byte:
+			formatted[i] = fmt.Sprintf("%q", arg)
+
+		default:
+			formatted[i] = fmt.Sprintf("%v", arg)
+		}
+	}
+
+	return fmt.Sprintf("%s(%s)", method, strings.Join(formatted, ", "))
+}
+
+// ForbidWritesFs wraps an afero.Fs and fails the test when an operation modifies the file system, see ForbidWrites.
+// Unlike afero.ReadOnlyFs, the forbidden operations fail the test and then return a *fs.PathError with
+// fs.ErrPermission.
+func ForbidWritesFs(tb testing.TB, fs afero.Fs) FsCallbacks {
+	tb.Helper()
+
+	return forbidOpsFs(tb, fs, true, append(append([]string(nil), fsWriteOps...), fileWriteOps...)...)
+}
+
+// ForbidOpsFs wraps an afero.Fs and fails the test when one of the given operations is called, see ForbidOps. The
+// forbidden operations fail the test and then return a *fs.PathError with fs.ErrPermission.
+func ForbidOpsFs(tb testing.TB, fs afero.Fs, ops ...string) FsCallbacks {
+	tb.Helper()
+
+	checkOps(tb, ops)
+
+	return forbidOpsFs(tb, fs, false, ops...)
+}
+
+func forbidOpsFs(tb testing.TB, fs afero.Fs, forbidOpenForWriting bool, ops ...string) FsCallbacks { //nolint: cyclop,funlen
+	forbidden := make(map[string]bool, len(ops))
+
+	for _, op := range ops {
+		forbidden[op] = true
+	}
+
+	fail := func(op, path string, args ...interface{}) error {
+		tb.Errorf("%s\n%s", forbiddenMessage(op, "", args...), callerStack())
+
+		return &os.PathError{Op: strings.ToLower(op), Path: path, Err: os.ErrPermission}
+	}
+
+	open := func(name string, f afero.File, err error) (afero.File, error) {
+		if err != nil {
+			return f, err
+		}
+
+		return forbidFileOps(tb, forbidden, name, f), nil
+	}
+
+	c := FsCallbacks{
+		OpenFunc: func(name string) (afero.File, error) {
+			if forbidden["Open"] {
+				return nil, fail("Open", name, name)
+			}
+
+			f, err := fs.Open(name)
+
+			return open(name, f, err)
+		},
+		OpenFileFunc: func(name string, flag int, perm os.FileMode) (afero.File, error) {
+			if forbidden["OpenFile"] || (forbidOpenForWriting && flag&writeFlags != 0) {
+				return nil, fail("OpenFile", name, name, flag, perm)
+			}
+
+			f, err := fs.OpenFile(name, flag, perm)
+
+			return open(name, f, err)
+		},
+		CreateFunc: func(name string) (afero.File, error) {
+			if forbidden["Create"] {
+				return nil, fail("Create", name, name)
+			}
+
+			f, err := fs.Create(name)
+
+			return open(name, f, err)
+		},
+	}
+
+	if forbidden["Chmod"] {
+		c.ChmodFunc = func(name string, mode os.FileMode) error {
+			return fail("Chmod", name, name, mode)
+		}
+	}
+
+	if forbidden["Chown"] {
+		c.ChownFunc = func(name string, uid, gid int) error {
+			return fail("Chown", name, name, uid, gid)
+		}
+	}
+
+	if forbidden["Chtimes"] {
+		c.ChtimesFunc = func(name string, atime, mtime time.Time) error {
+			return fail("Chtimes", name, name, atime, mtime)
+		}
+	}
+
+	if forbidden["Mkdir"] {
+		c.MkdirFunc = func(name string, perm os.FileMode) error {
+			return fail("Mkdir", name, name, perm)
+		}
+	}
+
+	if forbidden["MkdirAll"] {
+		c.MkdirAllFunc = func(path string, perm os.FileMode) error {
+			return fail("MkdirAll", path, path, perm)
+		}
+	}
+
+	if forbidden["Remove"] {
+		c.RemoveFunc = func(name string) error {
+			return fail("Remove", name, name)
+		}
+	}
+
+	if forbidden["RemoveAll"] {
+		c.RemoveAllFunc = func(path string) error {
+			return fail("RemoveAll", path, path)
+		}
+	}
+
+	if forbidden["Rename"] {
+		c.RenameFunc = func(oldname, newname string) error {
+			return fail("Rename", oldname, oldname, newname)
+		}
+	}
+
+	if forbidden["Name"] {
+		c.NameFunc = func() string {
+			_ = fail("Name", "") //nolint: errcheck
+
+			return ""
+		}
+	}
+
+	if forbidden["Stat"] {
+		c.StatFunc = func(name string) (os.FileInfo, error) {
+			return nil, fail("Stat", name, name)
+		}
+	}
+
+	return OverrideFs(fs, c)
+}
+
+func forbidFileOps(tb testing.TB, forbidden map[string]bool, name string, f afero.File) afero.File { //nolint: cyclop,funlen
+	fail := func(op string, args ...interface{}) error {
+		tb.Errorf("%s\n%s", forbiddenMessage(op, name, args...), callerStack())
+
+		return &fs.PathError{Op: strings.ToLower(op), Path: name, Err: fs.ErrPermission}
+	}
+
+	c := FileCallbacks{}
+
+	if forbidden["Close"] {
+		c.CloseFunc = func() error {
+			return fail("Close")
+		}
+	}
+
+	if forbidden["Name"] {
+		c.NameFunc = func() string {
+			_ = fail("Name") //nolint: errcheck
+
+			return ""
+		}
+	}
+
+	if forbidden["Read"] {
+		c.ReadFunc = func(p []byte) (int, error) {
+			return 0, fail("Read", p)
+		}
+	}
+
+	if forbidden["ReadAt"] {
+		c.ReadAtFunc = func(p []byte, off int64) (int, error) {
+			return 0, fail("ReadAt", p, off)
+		}
+	}
+
+	if forbidden["Readdir"] {
+		c.ReaddirFunc = func(count int) ([]fs.FileInfo, error) {
+			return nil, fail("Readdir", count)
+		}
+	}
+
+	if forbidden["Readdirnames"] {
+		c.ReaddirnamesFunc = func(n int) ([]string, error) {
+			return nil, fail("Readdirnames", n)
+		}
+	}
+
+	if forbidden["Seek"] {
+		c.SeekFunc = func(offset int64, whence int) (int64, error) {
+			return 0, fail("Seek", offset, whence)
+		}
+	}
+
+	if forbidden["Stat"] {
+		c.StatFunc = func() (fs.FileInfo, error) {
+			return nil, fail("Stat")
+		}
+	}
+
+	if forbidden["Sync"] {
+		c.SyncFunc = func() error {
+			return fail("Sync")
+		}
+	}
+
+	if forbidden["Truncate"] {
+		c.TruncateFunc = func(size int64) error {
+			return fail("Truncate", size)
+		}
+	}
+
+	if forbidden["Write"] {
+		c.WriteFunc = func(p []byte) (int, error) {
+			return 0, fail("Write", p)
+		}
+	}
+
+	if forbidden["WriteAt"] {
+		c.WriteAtFunc = func(p []byte, off int64) (int, error) {
+			return 0, fail("WriteAt", p, off)
+		}
+	}
+
+	if forbidden["WriteString"] {
+		c.WriteStringFunc = func(s string) (int, error) {
+			return 0, fail("WriteString", s)
+		}
+	}
+
+	return OverrideFile(f, c)
+}
@@ -0,0 +1,188 @@
+package aferomock_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestForbidWrites(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+
+	f := aferomock.MockFile(func(f *aferomock.File) {
+		f.On("Read", make([]byte, 5)).Return(5, nil)
+	})(t)
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		fs.On("Open", "config.yaml").Return(f, nil)
+		fs.On("Stat", "config.yaml").Return(aferomock.NopFileInfo(t), nil)
+		fs.On("Remove", "allowed.txt").Return(nil)
+
+		aferomock.ForbidWrites(ft, fs)
+	})(t)
+
+	// The allowed operations.
+	_, err := fs.Stat("config.yaml")
+	require.NoError(t, err)
+
+	opened, err := fs.Open("config.yaml")
+	require.NoError(t, err)
+
+	_, err = opened.Read(make([]byte, 5))
+	require.NoError(t, err)
+
+	require.NoError(t, fs.Remove("allowed.txt"))
+	assert.Empty(t, ft.Errors())
+
+	// The forbidden operations.
+	err = fs.Remove("data")
+	require.ErrorIs(t, err, os.ErrPermission)
+	assert.Equal(t, &os.PathError{Op: "remove", Path: "data", Err: os.ErrPermission}, err)
+
+	require.ErrorIs(t, fs.Rename("a", "b"), os.ErrPermission)
+
+	_, err = fs.OpenFile("data", os.O_WRONLY, 0o644)
+	require.ErrorIs(t, err, os.ErrPermission)
+
+	_, err = opened.WriteString("hello")
+	require.ErrorIs(t, err, os.ErrPermission)
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 4)
+	assert.Contains(t, errs[0], `aferomock: forbidden operation Remove("data")`+"\n\tgo.nhat.io/aferomock_test.TestForbidWrites\n")
+	assert.Contains(t, errs[1], `aferomock: forbidden operation Rename("a", "b")`)
+	assert.Contains(t, errs[2], `aferomock: forbidden operation OpenFile("data", 1, -rw-r--r--)`)
+	assert.Contains(t, errs[3], `aferomock: forbidden operation WriteString("hello") on file "config.yaml"`)
+}
+
+func TestForbidOps(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+	f := aferomock.NopFile(t)
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		fs.On("Create", "data.txt").Return(f, nil)
+		fs.On("Mkdir", "data", os.ModePerm).Return(nil)
+
+		aferomock.ForbidOps(ft, fs, "RemoveAll", "Stat", "Sync")
+	})(t)
+
+	require.NoError(t, fs.Mkdir("data", os.ModePerm))
+
+	created, err := fs.Create("data.txt")
+	require.NoError(t, err)
+
+	require.ErrorIs(t, fs.RemoveAll("data"), os.ErrPermission)
+
+	_, err = fs.Stat("data")
+	require.ErrorIs(t, err, os.ErrPermission)
+
+	require.ErrorIs(t, created.Sync(), os.ErrPermission)
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0], `aferomock: forbidden operation RemoveAll("data")`)
+	assert.Contains(t, errs[1], `aferomock: forbidden operation Stat("data")`)
+	assert.Contains(t, errs[2], `aferomock: forbidden operation Sync() on file "data.txt"`)
+}
+
+func TestForbidWritesFs(t *testing.T) {
+	t.Parallel()
+
+	base := afero.NewMemMapFs()
+
+	require.NoError(t, afero.WriteFile(base, "config.yaml", []byte("hello"), 0o644))
+
+	ft := newFakeT(t)
+	fs := aferomock.ForbidWritesFs(ft, base)
+
+	content, err := afero.ReadFile(fs, "config.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "hello", string(content))
+	assert.Empty(t, ft.Errors())
+
+	err = fs.Remove("config.yaml")
+	require.ErrorIs(t, err, os.ErrPermission)
+
+	_, err = fs.OpenFile("config.yaml", os.O_RDWR, 0o644)
+	require.ErrorIs(t, err, os.ErrPermission)
+
+	f, err := fs.Open("config.yaml")
+	require.NoError(t, err)
+
+	_, err = f.Write([]byte("x"))
+	require.ErrorIs(t, err, os.ErrPermission)
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0], `aferomock: forbidden operation Remove("config.yaml")`+"\n\tgo.nhat.io/aferomock_test.TestForbidWritesFs\n")
+	assert.Contains(t, errs[1], `aferomock: forbidden operation OpenFile("config.yaml", 2, -rw-r--r--)`)
+	assert.Contains(t, errs[2], `aferomock: forbidden operation Write("x") on file "config.yaml"`)
+
+	// The file system is not changed.
+	content, err = afero.ReadFile(base, "config.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "hello", string(content))
+}
+
+func TestForbidOpsFs(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+	fs := aferomock.ForbidOpsFs(ft, afero.NewMemMapFs(), "Stat", "Close")
+
+	_, err := fs.Stat("config.yaml")
+	require.ErrorIs(t, err, os.ErrPermission)
+
+	f, err := fs.Create("config.yaml")
+	require.NoError(t, err)
+
+	require.ErrorIs(t, f.Close(), os.ErrPermission)
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0], `aferomock: forbidden operation Stat("config.yaml")`)
+	assert.Contains(t, errs[1], `aferomock: forbidden operation Close() on file "config.yaml"`)
+}
+
+func TestForbidOps_UnknownOp(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+
+	aferomock.MockFs(func(fs *aferomock.Fs) {
+		aferomock.ForbidOps(ft, fs, "Remove", "Delete")
+	})(t)
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, `aferomock: unknown operation "Delete"`, errs[0])
+}
+
+func TestForbidOpsFs_UnknownOp(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+
+	aferomock.ForbidOpsFs(ft, afero.NewMemMapFs(), "Stat", "Lstat")
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, `aferomock: unknown operation "Lstat"`, errs[0])
+}
@@ -28,71 +28,186 @@ type FsCallbacks struct {
 	RemoveAllFunc func(path string) error
 	RenameFunc    func(oldname string, newname string) error
 	StatFunc      func(name string) (fs.FileInfo, error)
+
+	// Fallback is called when a callback is not set.
+	Fallback afero.Fs
+	// Unset is called when a callback is not set and there is no Fallback.
+	Unset UnsetFunc
+}
+
+func (fs FsCallbacks) unset(method string, args ...interface{}) error {
+	return unset(fs.Unset, "FsCallbacks", method, args...)
 }
 
 // Chmod satisfies the afero.Fs interface.
 func (fs FsCallbacks) Chmod(name string, mode fs.FileMode) error {
-	return fs.ChmodFunc(name, mode)
+	switch {
+	case fs.ChmodFunc != nil:
+		return fs.ChmodFunc(name, mode)
+
+	case fs.Fallback != nil:
+		return fs.Fallback.Chmod(name, mode)
+	}
+
+	return fs.unset("Chmod", name, mode)
 }
 
 // Chown satisfies the afero.Fs interface.
 func (fs FsCallbacks) Chown(name string, uid int, gid int) error {
-	return fs.ChownFunc(name, uid, gid)
+	switch {
+	case fs.ChownFunc != nil:
+		return fs.ChownFunc(name, uid, gid)
+
+	case fs.Fallback != nil:
+		return fs.Fallback.Chown(name, uid, gid)
+	}
+
+	return fs.unset("Chown", name, uid, gid)
 }
 
 // Chtimes satisfies the afero.Fs interface.
 func (fs FsCallbacks) Chtimes(name string, atime time.Time, mtime time.Time) error {
-	return fs.ChtimesFunc(name, atime, mtime)
+	switch {
+	case fs.ChtimesFunc != nil:
+		return fs.ChtimesFunc(name, atime, mtime)
+
+	case fs.Fallback != nil:
+		return fs.Fallback.Chtimes(name, atime, mtime)
+	}
+
+	return fs.unset("Chtimes", name, atime, mtime)
 }
 
 // Create satisfies the afero.Fs interface.
 func (fs FsCallbacks) Create(name string) (afero.File, error) {
-	return fs.CreateFunc(name)
+	switch {
+	case fs.CreateFunc != nil:
+		return fs.CreateFunc(name)
+
+	case fs.Fallback != nil:
+		return fs.Fallback.Create(name)
+	}
+
+	return nil, fs.unset("Create", name)
 }
 
 // Mkdir satisfies the afero.Fs interface.
 func (fs FsCallbacks) Mkdir(name string, perm fs.FileMode) error {
-	return fs.MkdirFunc(name, perm)
+	switch {
+	case fs.MkdirFunc != nil:
+		return fs.MkdirFunc(name, perm)
+
+	case fs.Fallback != nil:
+		return fs.Fallback.Mkdir(name, perm)
+	}
+
+	return fs.unset("Mkdir", name, perm)
 }
 
 // MkdirAll satisfies the afero.Fs interface.
 func (fs FsCallbacks) MkdirAll(path string, perm fs.FileMode) error {
-	return fs.MkdirAllFunc(path, perm)
+	switch {
+	case fs.MkdirAllFunc != nil:
+		return fs.MkdirAllFunc(path, perm)
+
+	case fs.Fallback != nil:
+		return fs.Fallback.MkdirAll(path, perm)
+	}
+
+	return fs.unset("MkdirAll", path, perm)
 }
 
 // Name satisfies the afero.Fs interface.
 func (fs FsCallbacks) Name() string {
-	return fs.NameFunc()
+	switch {
+	case fs.NameFunc != nil:
+		return fs.NameFunc()
+
+	case fs.Fallback != nil:
+		return fs.Fallback.Name()
+	}
+
+	_ = fs.unset("Name") //nolint: errcheck
+
+	return ""
 }
 
 // Open satisfies the afero.Fs interface.
 func (fs FsCallbacks) Open(name string) (afero.File, error) {
-	return fs.OpenFunc(name)
+	switch {
+	case fs.OpenFunc != nil:
+		return fs.OpenFunc(name)
+
+	case fs.Fallback != nil:
+		return fs.Fallback.Open(name)
+	}
+
+	return nil, fs.unset("Open", name)
 }
 
 // OpenFile satisfies the afero.Fs interface.
 func (fs FsCallbacks) OpenFile(name string, flag int, perm fs.FileMode) (afero.File, error) {
-	return fs.OpenFileFunc(name, flag, perm)
+	switch {
+	case fs.OpenFileFunc != nil:
+		return fs.OpenFileFunc(name, flag, perm)
+
+	case fs.Fallback != nil:
+		return fs.Fallback.OpenFile(name, flag, perm)
+	}
+
+	return nil, fs.unset("OpenFile", name, flag, perm)
 }
 
 // Remove satisfies the afero.Fs interface.
 func (fs FsCallbacks) Remove(name string) error {
-	return fs.RemoveFunc(name)
+	switch {
+	case fs.RemoveFunc != nil:
+		return fs.RemoveFunc(name)
+
+	case fs.Fallback != nil:
+		return fs.Fallback.Remove(name)
+	}
+
+	return fs.unset("Remove", name)
 }
 
 // RemoveAll satisfies the afero.Fs interface.
 func (fs FsCallbacks) RemoveAll(path string) error {
-	return fs.RemoveAllFunc(path)
+	switch {
+	case fs.RemoveAllFunc != nil:
+		return fs.RemoveAllFunc(path)
+
+	case fs.Fallback != nil:
+		return fs.Fallback.RemoveAll(path)
+	}
+
+	return fs.unset("RemoveAll", path)
 }
 
 // Rename satisfies the afero.Fs interface.
 func (fs FsCallbacks) Rename(oldname string, newname string) error {
-	return fs.RenameFunc(oldname, newname)
+	switch {
+	case fs.RenameFunc != nil:
+		return fs.RenameFunc(oldname, newname)
+
+	case fs.Fallback != nil:
+		return fs.Fallback.Rename(oldname, newname)
+	}
+
+	return fs.unset("Rename", oldname, newname)
 }
 
 // Stat satisfies the afero.Fs interface.
 func (fs FsCallbacks) Stat(name string) (fs.FileInfo, error) {
-	return fs.StatFunc(name)
+	switch {
+	case fs.StatFunc != nil:
+		return fs.StatFunc(name)
+
+	case fs.Fallback != nil:
+		return fs.Fallback.Stat(name)
+	}
+
+	return nil, fs.unset("Stat", name)
 }
 
 // WrapFs wraps a afero.Fs with custom callbacks.
@@ -0,0 +1,81 @@
+package aferomock_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// fakeT is a testing.TB that records the failures and the cleanup functions instead of failing the test.
+type fakeT struct {
+	testing.TB
+
+	mu       sync.Mutex
+	errors   []string
+	cleanups []func()
+}
+
+func newFakeT(tb testing.TB) *fakeT {
+	tb.Helper()
+
+	return &fakeT{TB: tb}
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Cleanup(f func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *fakeT) Error(args ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.errors = append(t.errors, fmt.Sprint(args...))
+}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Fatalf(format string, args ...any) {
+	t.Errorf(format, args...)
+
+	panic("fatal")
+}
+
+func (t *fakeT) FailNow() {
+	panic("fail now")
+}
+
+func (t *fakeT) Failed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.errors) > 0
+}
+
+func (t *fakeT) Errors() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]string(nil), t.errors...)
+}
+
+// RunCleanup runs the cleanup functions in the reverse order of registration.
+func (t *fakeT) RunCleanup() {
+	t.mu.Lock()
+	cleanups := t.cleanups
+	t.cleanups = nil
+	t.mu.Unlock()
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
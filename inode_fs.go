@@ -0,0 +1,986 @@
+package aferomock
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+var errWriteAtInAppendMode = errors.New("os: invalid use of WriteAt on file opened with O_APPEND")
+
+// maxSymlinkHops is the maximum number of symbolic links that are followed to resolve a path, like Linux.
+const maxSymlinkHops = 40
+
+// InodeFs is an in-memory afero.Fs that models inodes: a directory entry points to an inode that holds the data, so a
+// file can have several names. LinkIfPossible creates a hard link, Remove decrements the number of links, and the data
+// is shared by all the names of the file. FileInfo.Sys reports the inode number and the number of links as a StatSys.
+//
+// InodeFs implements afero.Symlinker with the resolution rules of Unix: relative targets are resolved from the
+// directory of the link, chains are followed up to 40 hops before failing with syscall.ELOOP, dangling links can be
+// read with Lstat and Readlink, and OpenFile with syscall.O_NOFOLLOW fails with syscall.ELOOP on a link. Remove,
+// Rename and LinkIfPossible act on the link itself.
+//
+// The callbacks of the embedded SymlinkerCallbacks can be replaced to inject errors, and FollowFunc is called at every
+// hop of the resolution of a link to inject errors at a specific hop.
+//
+//	fs := aferomock.NewInodeFs()
+//
+//	_ = afero.WriteFile(fs, "a.txt", []byte("hello"), 0o644)
+//	_ = fs.LinkIfPossible("a.txt", "b.txt")
+//	_ = fs.SymlinkIfPossible("a.txt", "c.txt")
+type InodeFs struct {
+	SymlinkerCallbacks
+
+	// FollowFunc is called with the absolute path of a link and its target before the link is followed, the error is
+	// returned by the operation that resolves the path. It is called with the InodeFs locked, so it must not use it.
+	FollowFunc func(link, target string) error
+
+	tree *inodeTree
+}
+
+// NewInodeFs creates a new empty InodeFs.
+func NewInodeFs() *InodeFs {
+	t := &inodeTree{}
+	t.root = t.newInode(os.ModeDir | 0o755)
+
+	return newInodeFs(t)
+}
+
+func newInodeFs(t *inodeTree) *InodeFs {
+	fs := &InodeFs{
+		SymlinkerCallbacks: SymlinkerCallbacks{
+			FsCallbacks: FsCallbacks{
+				ChmodFunc:     t.chmod,
+				ChownFunc:     t.chown,
+				ChtimesFunc:   t.chtimes,
+				CreateFunc:    t.create,
+				MkdirFunc:     t.mkdir,
+				MkdirAllFunc:  t.mkdirAll,
+				NameFunc:      func() string { return "InodeFs" },
+				OpenFunc:      t.open,
+				OpenFileFunc:  t.openFile,
+				RemoveFunc:    t.remove,
+				RemoveAllFunc: t.removeAll,
+				RenameFunc:    t.rename,
+				StatFunc:      t.stat,
+			},
+			LstatIfPossibleFunc:    t.lstat,
+			SymlinkIfPossibleFunc:  t.symlink,
+			ReadlinkIfPossibleFunc: t.readlink,
+		},
+		tree: t,
+	}
+
+	t.follow = func(link, target string) error {
+		if fs.FollowFunc == nil {
+			return nil
+		}
+
+		return fs.FollowFunc(link, target)
+	}
+
+	return fs
+}
+
+// LinkIfPossible creates newname as a hard link to the oldname file. Directories can not be linked, a symbolic link is
+// not followed.
+func (fs *InodeFs) LinkIfPossible(oldname, newname string) error {
+	return fs.tree.link(oldname, newname)
+}
+
+type inode struct {
+	ino     uint64
+	mode    os.FileMode
+	nlink   uint64
+	uid     int
+	gid     int
+	atime   time.Time
+	mtime   time.Time
+	data    []byte
+	entries map[string]*inode
+	target  string
+
+	// shared indicates that the data is shared with a copy of the inode, it is copied before it is modified.
+	shared bool
+}
+
+// own copies the data of the inode if it is shared, so that it can be modified.
+func (n *inode) own() {
+	if n.shared {
+		n.data = append([]byte(nil), n.data...)
+		n.shared = false
+	}
+}
+
+type inodeTree struct {
+	mu      sync.RWMutex
+	root    *inode
+	lastIno uint64
+	follow  func(link, target string) error
+}
+
+func (t *inodeTree) newInode(mode os.FileMode) *inode {
+	t.lastIno++
+
+	now := time.Now()
+	n := &inode{ino: t.lastIno, mode: mode, atime: now, mtime: now}
+
+	if mode.IsDir() {
+		n.entries = make(map[string]*inode)
+	}
+
+	return n
+}
+
+// cleanPath returns the absolute slash-separated form of the path.
+func cleanPath(name string) string {
+	return path.Clean("/" + filepath.ToSlash(name))
+}
+
+// splitPath splits a path into its elements, the root has no elements.
+func splitPath(name string) []string {
+	p := cleanPath(name)
+	if p == "/" {
+		return nil
+	}
+
+	return strings.Split(p[1:], "/")
+}
+
+// splitLink splits a path into its elements without cleaning it, so that ".." is resolved after the links that precede
+// it are followed.
+func splitLink(name string) []string {
+	var elems []string
+
+	for _, elem := range strings.Split(filepath.ToSlash(name), "/") {
+		if elem != "" && elem != "." {
+			elems = append(elems, elem)
+		}
+	}
+
+	return elems
+}
+
+// resolve resolves the symbolic links of the path, and returns the directory that contains it and its base name. The
+// directory is nil for the root. The links of the directories are always followed, the link of the base name is only
+// followed when follow is true. The base name may not exist.
+func (t *inodeTree) resolve(op, name string, follow bool) (*inode, string, error) { //nolint: cyclop
+	pathErr := func(err error) error {
+		return &os.PathError{Op: op, Path: name, Err: err}
+	}
+
+	dirs := []*inode{t.root}
+	elems := []string(nil)
+	pending := splitLink(name)
+	hops := 0
+
+	for len(pending) > 0 {
+		elem := pending[0]
+		pending = pending[1:]
+
+		if elem == ".." {
+			if len(elems) > 0 {
+				dirs, elems = dirs[:len(dirs)-1], elems[:len(elems)-1]
+			}
+
+			continue
+		}
+
+		dir := dirs[len(dirs)-1]
+
+		n, ok := dir.entries[elem]
+		if !ok {
+			if len(pending) > 0 {
+				return nil, "", pathErr(os.ErrNotExist)
+			}
+
+			return dir, elem, nil
+		}
+
+		if n.mode&os.ModeSymlink != 0 && (len(pending) > 0 || follow) {
+			if hops++; hops > maxSymlinkHops {
+				return nil, "", pathErr(syscall.ELOOP)
+			}
+
+			if err := t.follow("/"+path.Join(append(elems, elem)...), n.target); err != nil {
+				return nil, "", pathErr(err)
+			}
+
+			target := filepath.ToSlash(n.target)
+			if path.IsAbs(target) {
+				dirs, elems = dirs[:1], nil
+			}
+
+			pending = append(splitLink(target), pending...)
+
+			continue
+		}
+
+		if len(pending) == 0 {
+			return dir, elem, nil
+		}
+
+		if !n.mode.IsDir() {
+			return nil, "", pathErr(syscall.ENOTDIR)
+		}
+
+		dirs, elems = append(dirs, n), append(elems, elem)
+	}
+
+	if len(elems) == 0 {
+		return nil, "", nil
+	}
+
+	return dirs[len(dirs)-2], elems[len(elems)-1], nil
+}
+
+// lookup returns the inode at the path, following the symbolic links.
+func (t *inodeTree) lookup(op, name string) (*inode, error) {
+	return t.lookupFollow(op, name, true)
+}
+
+// lookupFollow returns the inode at the path, the link of the base name is only followed when follow is true.
+func (t *inodeTree) lookupFollow(op, name string, follow bool) (*inode, error) {
+	dir, base, err := t.resolve(op, name, follow)
+	if err != nil {
+		return nil, err
+	}
+
+	if dir == nil {
+		return t.root, nil
+	}
+
+	n, ok := dir.entries[base]
+	if !ok {
+		return nil, &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	}
+
+	return n, nil
+}
+
+// lookupParent returns the directory that contains the path, and the base name of the path. The link of the base name
+// is not followed.
+func (t *inodeTree) lookupParent(op, name string) (*inode, string, error) {
+	dir, base, err := t.resolve(op, name, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if dir == nil {
+		return nil, "", &os.PathError{Op: op, Path: name, Err: syscall.EINVAL}
+	}
+
+	return dir, base, nil
+}
+
+func (t *inodeTree) fileInfo(name string, n *inode) os.FileInfo {
+	base := path.Base(cleanPath(name))
+	size := int64(len(n.data))
+	nlink := n.nlink
+
+	if n.mode&os.ModeSymlink != 0 {
+		size = int64(len(n.target))
+	}
+
+	if n.mode.IsDir() {
+		nlink = 2
+
+		for _, c := range n.entries {
+			if c.mode.IsDir() {
+				nlink++
+			}
+		}
+	}
+
+	sys := NewStatSys().
+		WithMode(n.mode).
+		WithSize(size).
+		WithAtime(n.atime).
+		WithMtime(n.mtime).
+		WithCtime(n.mtime).
+		WithIno(n.ino).
+		WithNlink(nlink).
+		WithUID(uint32(n.uid)). //nolint: gosec
+		WithGID(uint32(n.gid))  //nolint: gosec
+
+	mode, mtime := n.mode, n.mtime
+
+	return FileInfoCallbacks{
+		NameFunc:    func() string { return base },
+		SizeFunc:    func() int64 { return size },
+		ModeFunc:    func() os.FileMode { return mode },
+		ModTimeFunc: func() time.Time { return mtime },
+		IsDirFunc:   func() bool { return mode.IsDir() },
+		SysFunc:     sys.SysFunc(),
+	}
+}
+
+func (t *inodeTree) chmod(name string, mode os.FileMode) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n, err := t.lookup("chmod", name)
+	if err != nil {
+		return err
+	}
+
+	n.mode = n.mode&^os.ModePerm | mode.Perm()
+
+	return nil
+}
+
+func (t *inodeTree) chown(name string, uid, gid int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n, err := t.lookup("chown", name)
+	if err != nil {
+		return err
+	}
+
+	n.uid, n.gid = uid, gid
+
+	return nil
+}
+
+func (t *inodeTree) chtimes(name string, atime, mtime time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n, err := t.lookup("chtimes", name)
+	if err != nil {
+		return err
+	}
+
+	n.atime, n.mtime = atime, mtime
+
+	return nil
+}
+
+func (t *inodeTree) create(name string) (afero.File, error) {
+	return t.openFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o666)
+}
+
+func (t *inodeTree) mkdir(name string, perm os.FileMode) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.mkdirLocked(name, perm)
+}
+
+func (t *inodeTree) mkdirLocked(name string, perm os.FileMode) error {
+	dir, base, err := t.lookupParent("mkdir", name)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := dir.entries[base]; ok {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrExist}
+	}
+
+	t.addEntry(dir, base, t.newInode(os.ModeDir|perm.Perm()))
+
+	return nil
+}
+
+func (t *inodeTree) mkdirAll(name string, perm os.FileMode) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	elems := splitPath(name)
+
+	for i := range elems {
+		p := "/" + path.Join(elems[:i+1]...)
+
+		n, err := t.lookup("mkdir", p)
+
+		switch {
+		case err == nil && !n.mode.IsDir():
+			return &os.PathError{Op: "mkdir", Path: p, Err: syscall.ENOTDIR}
+
+		case err == nil:
+			continue
+
+		case !errors.Is(err, os.ErrNotExist):
+			return err
+		}
+
+		if err := t.mkdirLocked(p, perm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *inodeTree) addEntry(dir *inode, base string, n *inode) {
+	dir.entries[base] = n
+	dir.mtime = time.Now()
+	n.nlink++
+}
+
+func (t *inodeTree) removeEntry(dir *inode, base string) {
+	n := dir.entries[base]
+
+	delete(dir.entries, base)
+
+	dir.mtime = time.Now()
+	n.nlink--
+}
+
+func (t *inodeTree) open(name string) (afero.File, error) {
+	return t.openFile(name, os.O_RDONLY, 0)
+}
+
+func (t *inodeTree) openFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	excl := flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0
+
+	// Like open(2), a link is not followed with O_NOFOLLOW, nor with O_CREATE and O_EXCL.
+	dir, base, err := t.resolve("open", name, flag&oNoFollow == 0 && !excl)
+	if err != nil {
+		return nil, err
+	}
+
+	n := t.root
+	if dir != nil {
+		n = dir.entries[base]
+	}
+
+	switch {
+	case n != nil && excl:
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrExist}
+
+	case n != nil && n.mode&os.ModeSymlink != 0:
+		return nil, &os.PathError{Op: "open", Path: name, Err: syscall.ELOOP}
+
+	case n == nil && flag&os.O_CREATE != 0:
+		n = t.newInode(perm.Perm())
+
+		t.addEntry(dir, base, n)
+
+	case n == nil:
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+
+	if n.mode.IsDir() && writable {
+		return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EISDIR}
+	}
+
+	if writable && flag&os.O_TRUNC != 0 {
+		n.data = nil
+		n.mtime = time.Now()
+	}
+
+	f := &inodeFile{tree: t, name: name, node: n, flag: flag}
+
+	return f.callbacks(), nil
+}
+
+func (t *inodeTree) remove(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	dir, base, err := t.lookupParent("remove", name)
+	if err != nil {
+		return err
+	}
+
+	n, ok := dir.entries[base]
+	if !ok {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrNotExist}
+	}
+
+	if n.mode.IsDir() && len(n.entries) > 0 {
+		return &os.PathError{Op: "remove", Path: name, Err: syscall.ENOTEMPTY}
+	}
+
+	t.removeEntry(dir, base)
+
+	return nil
+}
+
+func (t *inodeTree) removeAll(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(splitPath(name)) == 0 {
+		for base := range t.root.entries {
+			t.removeEntry(t.root, base)
+		}
+
+		return nil
+	}
+
+	dir, base, err := t.lookupParent("removeall", name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if _, ok := dir.entries[base]; ok {
+		t.removeEntry(dir, base)
+	}
+
+	return nil
+}
+
+func (t *inodeTree) rename(oldname, newname string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	linkErr := func(err error) error {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+
+	oldDir, oldBase, err := t.lookupParent("rename", oldname)
+	if err != nil {
+		return linkErr(errors.Unwrap(err))
+	}
+
+	n, ok := oldDir.entries[oldBase]
+	if !ok {
+		return linkErr(os.ErrNotExist)
+	}
+
+	newDir, newBase, err := t.lookupParent("rename", newname)
+	if err != nil {
+		return linkErr(errors.Unwrap(err))
+	}
+
+	// A directory can not be moved into itself.
+	if n.mode.IsDir() && strings.HasPrefix(cleanPath(newname), cleanPath(oldname)+"/") {
+		return linkErr(syscall.EINVAL)
+	}
+
+	if existing, ok := newDir.entries[newBase]; ok {
+		switch {
+		case existing == n:
+			return nil
+
+		case existing.mode.IsDir() && !n.mode.IsDir():
+			return linkErr(syscall.EISDIR)
+
+		case !existing.mode.IsDir() && n.mode.IsDir():
+			return linkErr(syscall.ENOTDIR)
+
+		case existing.mode.IsDir() && len(existing.entries) > 0:
+			return linkErr(syscall.ENOTEMPTY)
+		}
+
+		t.removeEntry(newDir, newBase)
+	}
+
+	t.removeEntry(oldDir, oldBase)
+	t.addEntry(newDir, newBase, n)
+
+	return nil
+}
+
+func (t *inodeTree) stat(name string) (os.FileInfo, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	n, err := t.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.fileInfo(name, n), nil
+}
+
+func (t *inodeTree) link(oldname, newname string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	linkErr := func(err error) error {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: err}
+	}
+
+	n, err := t.lookupFollow("link", oldname, false)
+	if err != nil {
+		return linkErr(errors.Unwrap(err))
+	}
+
+	if n.mode.IsDir() {
+		return linkErr(syscall.EPERM)
+	}
+
+	dir, base, err := t.lookupParent("link", newname)
+	if err != nil {
+		return linkErr(errors.Unwrap(err))
+	}
+
+	if _, ok := dir.entries[base]; ok {
+		return linkErr(os.ErrExist)
+	}
+
+	t.addEntry(dir, base, n)
+
+	return nil
+}
+
+func (t *inodeTree) lstat(name string) (os.FileInfo, bool, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	n, err := t.lookupFollow("lstat", name, false)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return t.fileInfo(name, n), true, nil
+}
+
+func (t *inodeTree) symlink(oldname, newname string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	linkErr := func(err error) error {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: err}
+	}
+
+	if oldname == "" {
+		return linkErr(os.ErrNotExist)
+	}
+
+	dir, base, err := t.lookupParent("symlink", newname)
+	if err != nil {
+		return linkErr(errors.Unwrap(err))
+	}
+
+	if _, ok := dir.entries[base]; ok {
+		return linkErr(os.ErrExist)
+	}
+
+	n := t.newInode(os.ModeSymlink | os.ModePerm)
+	n.target = oldname
+
+	t.addEntry(dir, base, n)
+
+	return nil
+}
+
+func (t *inodeTree) readlink(name string) (string, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	n, err := t.lookupFollow("readlink", name, false)
+	if err != nil {
+		return "", err
+	}
+
+	if n.mode&os.ModeSymlink == 0 {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: syscall.EINVAL}
+	}
+
+	return n.target, nil
+}
+
+// inodeFile is an open file of an InodeFs.
+type inodeFile struct {
+	tree *inodeTree
+	name string
+	node *inode
+	flag int
+
+	offset    int64
+	dirOffset int
+	closed    bool
+}
+
+func (f *inodeFile) callbacks() FileCallbacks {
+	return FileCallbacks{
+		CloseFunc:        f.close,
+		NameFunc:         func() string { return f.name },
+		ReadFunc:         f.read,
+		ReadAtFunc:       f.readAt,
+		ReaddirFunc:      f.readdir,
+		ReaddirnamesFunc: f.readdirnames,
+		SeekFunc:         f.seek,
+		StatFunc:         f.stat,
+		SyncFunc:         f.sync,
+		TruncateFunc:     f.truncate,
+		WriteFunc:        f.write,
+		WriteAtFunc:      f.writeAt,
+		WriteStringFunc: func(s string) (int, error) {
+			return f.write([]byte(s))
+		},
+	}
+}
+
+func (f *inodeFile) pathError(op string, err error) error {
+	return &os.PathError{Op: op, Path: f.name, Err: err}
+}
+
+// check returns an error if the file is closed, or if it is not open for reading or writing.
+func (f *inodeFile) check(op string, write bool) error {
+	if f.closed {
+		return f.pathError(op, os.ErrClosed)
+	}
+
+	if write && f.flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return f.pathError(op, syscall.EBADF)
+	}
+
+	if !write && f.flag&os.O_WRONLY != 0 {
+		return f.pathError(op, syscall.EBADF)
+	}
+
+	return nil
+}
+
+func (f *inodeFile) close() error {
+	f.tree.mu.Lock()
+	defer f.tree.mu.Unlock()
+
+	if f.closed {
+		return f.pathError("close", os.ErrClosed)
+	}
+
+	f.closed = true
+
+	return nil
+}
+
+func (f *inodeFile) read(p []byte) (int, error) {
+	f.tree.mu.Lock()
+	defer f.tree.mu.Unlock()
+
+	n, err := f.readAtLocked("read", p, f.offset)
+	f.offset += int64(n)
+
+	if errors.Is(err, io.EOF) && n > 0 {
+		err = nil
+	}
+
+	return n, err
+}
+
+func (f *inodeFile) readAt(p []byte, off int64) (int, error) {
+	f.tree.mu.Lock()
+	defer f.tree.mu.Unlock()
+
+	return f.readAtLocked("read", p, off)
+}
+
+func (f *inodeFile) readAtLocked(op string, p []byte, off int64) (int, error) {
+	if err := f.check(op, false); err != nil {
+		return 0, err
+	}
+
+	if f.node.mode.IsDir() {
+		return 0, f.pathError(op, syscall.EISDIR)
+	}
+
+	if off < 0 {
+		return 0, f.pathError(op, syscall.EINVAL)
+	}
+
+	if off >= int64(len(f.node.data)) {
+		return 0, io.EOF
+	}
+
+	n := copy(p, f.node.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+func (f *inodeFile) readdir(count int) ([]os.FileInfo, error) {
+	f.tree.mu.Lock()
+	defer f.tree.mu.Unlock()
+
+	names, err := f.readdirnamesLocked(count)
+
+	infos := make([]os.FileInfo, len(names))
+
+	for i, name := range names {
+		infos[i] = f.tree.fileInfo(name, f.node.entries[name])
+	}
+
+	return infos, err
+}
+
+func (f *inodeFile) readdirnames(count int) ([]string, error) {
+	f.tree.mu.Lock()
+	defer f.tree.mu.Unlock()
+
+	return f.readdirnamesLocked(count)
+}
+
+func (f *inodeFile) readdirnamesLocked(count int) ([]string, error) {
+	if err := f.check("readdirent", false); err != nil {
+		return nil, err
+	}
+
+	if !f.node.mode.IsDir() {
+		return nil, f.pathError("readdirent", syscall.ENOTDIR)
+	}
+
+	names := make([]string, 0, len(f.node.entries))
+
+	for name := range f.node.entries {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	names = names[min(f.dirOffset, len(names)):]
+
+	if count > 0 {
+		if len(names) == 0 {
+			return nil, io.EOF
+		}
+
+		names = names[:min(count, len(names))]
+	}
+
+	f.dirOffset += len(names)
+
+	return names, nil
+}
+
+func (f *inodeFile) seek(offset int64, whence int) (int64, error) {
+	f.tree.mu.Lock()
+	defer f.tree.mu.Unlock()
+
+	if f.closed {
+		return 0, f.pathError("seek", os.ErrClosed)
+	}
+
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+
+	case io.SeekEnd:
+		offset += int64(len(f.node.data))
+	}
+
+	if offset < 0 {
+		return 0, f.pathError("seek", syscall.EINVAL)
+	}
+
+	f.offset = offset
+
+	return offset, nil
+}
+
+func (f *inodeFile) stat() (os.FileInfo, error) {
+	f.tree.mu.RLock()
+	defer f.tree.mu.RUnlock()
+
+	if f.closed {
+		return nil, f.pathError("stat", os.ErrClosed)
+	}
+
+	return f.tree.fileInfo(f.name, f.node), nil
+}
+
+func (f *inodeFile) sync() error {
+	f.tree.mu.RLock()
+	defer f.tree.mu.RUnlock()
+
+	if f.closed {
+		return f.pathError("sync", os.ErrClosed)
+	}
+
+	return nil
+}
+
+func (f *inodeFile) truncate(size int64) error {
+	f.tree.mu.Lock()
+	defer f.tree.mu.Unlock()
+
+	if err := f.check("truncate", true); err != nil {
+		return err
+	}
+
+	if size < 0 {
+		return f.pathError("truncate", syscall.EINVAL)
+	}
+
+	f.resize(size)
+	f.node.mtime = time.Now()
+
+	return nil
+}
+
+func (f *inodeFile) write(p []byte) (int, error) {
+	f.tree.mu.Lock()
+	defer f.tree.mu.Unlock()
+
+	if f.flag&os.O_APPEND != 0 {
+		f.offset = int64(len(f.node.data))
+	}
+
+	n, err := f.writeAtLocked("write", p, f.offset)
+	f.offset += int64(n)
+
+	return n, err
+}
+
+func (f *inodeFile) writeAt(p []byte, off int64) (int, error) {
+	f.tree.mu.Lock()
+	defer f.tree.mu.Unlock()
+
+	if f.flag&os.O_APPEND != 0 {
+		return 0, errWriteAtInAppendMode
+	}
+
+	return f.writeAtLocked("write", p, off)
+}
+
+func (f *inodeFile) writeAtLocked(op string, p []byte, off int64) (int, error) {
+	if err := f.check(op, true); err != nil {
+		return 0, err
+	}
+
+	if off < 0 {
+		return 0, f.pathError(op, syscall.EINVAL)
+	}
+
+	f.node.own()
+
+	if end := off + int64(len(p)); end > int64(len(f.node.data)) {
+		f.resize(end)
+	}
+
+	copy(f.node.data[off:], p)
+
+	f.node.mtime = time.Now()
+
+	return len(p), nil
+}
+
+// resize resizes the data of the inode, the new bytes are zeros.
+func (f *inodeFile) resize(size int64) {
+	if size <= int64(len(f.node.data)) {
+		f.node.data = f.node.data[:size]
+
+		return
+	}
+
+	data := make([]byte, size)
+	copy(data, f.node.data)
+
+	f.node.data = data
+}
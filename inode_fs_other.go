@@ -0,0 +1,6 @@
+//go:build !linux && !darwin
+
+package aferomock
+
+// oNoFollow is the flag of OpenFile that prevents following a symbolic link, it is not supported on this platform.
+const oNoFollow = 0
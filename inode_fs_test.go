@@ -0,0 +1,606 @@
+//go:build linux || darwin
+
+package aferomock_test
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestInodeFs_LinkIfPossible(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	require.NoError(t, fs.MkdirAll("data", os.ModePerm))
+	require.NoError(t, afero.WriteFile(fs, "data/a.txt", []byte("hello"), 0o644))
+	require.NoError(t, fs.LinkIfPossible("data/a.txt", "b.txt"))
+
+	a, err := fs.Stat("data/a.txt")
+	require.NoError(t, err)
+
+	b, err := fs.Stat("b.txt")
+	require.NoError(t, err)
+
+	assert.Equal(t, "b.txt", b.Name())
+	assert.Equal(t, statT(t, a).Ino, statT(t, b).Ino)
+	assert.EqualValues(t, 2, statT(t, a).Nlink)
+	assert.EqualValues(t, 2, statT(t, b).Nlink)
+
+	// A write through one name is visible through the other.
+	f, err := fs.OpenFile("b.txt", os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+
+	_, err = f.WriteString(" world")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	data, err := afero.ReadFile(fs, "data/a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+
+	// Remove decrements the number of links, the data is kept by the other name.
+	require.NoError(t, fs.Remove("data/a.txt"))
+
+	b, err = fs.Stat("b.txt")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, statT(t, b).Nlink)
+
+	data, err = afero.ReadFile(fs, "b.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+}
+
+func TestInodeFs_LinkIfPossible_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	require.NoError(t, fs.Mkdir("dir", os.ModePerm))
+	require.NoError(t, afero.WriteFile(fs, "a.txt", nil, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "b.txt", nil, 0o644))
+
+	testCases := []struct {
+		scenario      string
+		oldname       string
+		newname       string
+		expectedError error
+	}{
+		{scenario: "missing", oldname: "missing.txt", newname: "c.txt", expectedError: os.ErrNotExist},
+		{scenario: "exists", oldname: "a.txt", newname: "b.txt", expectedError: os.ErrExist},
+		{scenario: "dir", oldname: "dir", newname: "c", expectedError: syscall.EPERM},
+		{scenario: "missing parent", oldname: "a.txt", newname: "missing/c.txt", expectedError: os.ErrNotExist},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			err := fs.LinkIfPossible(tc.oldname, tc.newname)
+
+			var linkErr *os.LinkError
+
+			require.ErrorAs(t, err, &linkErr)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Equal(t, "link", linkErr.Op)
+		})
+	}
+}
+
+func TestInodeFs_OpenFileAfterRemove(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	f, err := fs.Create("a.txt")
+	require.NoError(t, err)
+
+	require.NoError(t, fs.Remove("a.txt"))
+
+	// The open file keeps the inode.
+	_, err = f.WriteString("hello")
+	require.NoError(t, err)
+
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	fi, err := f.Stat()
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, statT(t, fi).Nlink)
+
+	require.NoError(t, f.Close())
+	require.ErrorIs(t, f.Close(), os.ErrClosed)
+
+	_, err = fs.Stat("a.txt")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestInodeFs_Rename(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	require.NoError(t, fs.MkdirAll("a/b", os.ModePerm))
+	require.NoError(t, afero.WriteFile(fs, "a/b/c.txt", []byte("c"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "d.txt", []byte("d"), 0o644))
+
+	before, err := fs.Stat("a/b/c.txt")
+	require.NoError(t, err)
+
+	require.NoError(t, fs.Rename("a", "e"))
+
+	after, err := fs.Stat("e/b/c.txt")
+	require.NoError(t, err)
+	assert.Equal(t, statT(t, before).Ino, statT(t, after).Ino)
+
+	// Renaming over a file replaces it.
+	require.NoError(t, fs.Rename("d.txt", "e/b/c.txt"))
+
+	data, err := afero.ReadFile(fs, "e/b/c.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "d", string(data))
+
+	require.ErrorIs(t, fs.Rename("e", "e/b/f"), syscall.EINVAL)
+	require.ErrorIs(t, fs.Rename("missing", "f"), os.ErrNotExist)
+	require.ErrorIs(t, fs.Rename("e/b/c.txt", "e"), syscall.EISDIR)
+}
+
+func TestInodeFs_Errors(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	require.NoError(t, fs.MkdirAll("dir/sub", os.ModePerm))
+	require.NoError(t, afero.WriteFile(fs, "file.txt", []byte("hello"), 0o644))
+
+	_, err := fs.Stat("missing")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = fs.Stat("file.txt/x")
+	require.ErrorIs(t, err, syscall.ENOTDIR)
+
+	require.ErrorIs(t, fs.Mkdir("dir", os.ModePerm), os.ErrExist)
+	require.ErrorIs(t, fs.MkdirAll("file.txt/x", os.ModePerm), syscall.ENOTDIR)
+	require.ErrorIs(t, fs.Remove("dir"), syscall.ENOTEMPTY)
+	require.ErrorIs(t, fs.Remove("missing"), os.ErrNotExist)
+
+	_, err = fs.OpenFile("file.txt", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	require.ErrorIs(t, err, os.ErrExist)
+
+	_, err = fs.OpenFile("dir", os.O_WRONLY, 0)
+	require.ErrorIs(t, err, syscall.EISDIR)
+
+	f, err := fs.Open("file.txt")
+	require.NoError(t, err)
+
+	_, err = f.Write([]byte("x"))
+	require.ErrorIs(t, err, syscall.EBADF)
+
+	_, err = f.Readdir(-1)
+	require.ErrorIs(t, err, syscall.ENOTDIR)
+
+	require.NoError(t, f.Close())
+
+	require.NoError(t, fs.RemoveAll("dir"))
+	require.NoError(t, fs.RemoveAll("missing"))
+
+	_, err = fs.Stat("dir/sub")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestInodeFs_File(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	f, err := fs.Create("file.txt")
+	require.NoError(t, err)
+
+	_, err = f.WriteAt([]byte("world"), 6)
+	require.NoError(t, err)
+
+	_, err = f.WriteAt([]byte("hello"), 0)
+	require.NoError(t, err)
+
+	p := make([]byte, 5)
+
+	n, err := f.ReadAt(p, 6)
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(p[:n]))
+
+	_, err = f.ReadAt(p, 8)
+	require.ErrorIs(t, err, io.EOF)
+
+	require.NoError(t, f.Truncate(5))
+
+	fi, err := f.Stat()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), fi.Size())
+	assert.Equal(t, os.FileMode(0o666), fi.Mode())
+
+	pos, err := f.Seek(-2, io.SeekEnd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), pos)
+
+	_, err = f.Seek(-10, io.SeekCurrent)
+	require.ErrorIs(t, err, syscall.EINVAL)
+
+	require.NoError(t, f.Sync())
+	require.NoError(t, f.Close())
+
+	_, err = f.Read(p)
+	require.ErrorIs(t, err, os.ErrClosed)
+
+	a, err := fs.OpenFile("file.txt", os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+
+	_, err = a.WriteAt([]byte("x"), 0)
+	require.Error(t, err)
+	require.NoError(t, a.Close())
+}
+
+func TestInodeFs_Readdir(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	require.NoError(t, fs.MkdirAll("dir/c", os.ModePerm))
+	require.NoError(t, afero.WriteFile(fs, "dir/b.txt", []byte("b"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "dir/a.txt", []byte("a"), 0o644))
+
+	d, err := fs.Open("dir")
+	require.NoError(t, err)
+
+	names, err := d.Readdirnames(2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, names)
+
+	fis, err := d.Readdir(2)
+	require.NoError(t, err)
+	require.Len(t, fis, 1)
+	assert.Equal(t, "c", fis[0].Name())
+	assert.True(t, fis[0].IsDir())
+
+	_, err = d.Readdir(1)
+	require.ErrorIs(t, err, io.EOF)
+
+	fis, err = d.Readdir(-1)
+	require.NoError(t, err)
+	assert.Empty(t, fis)
+
+	require.NoError(t, d.Close())
+
+	fi, err := fs.Stat("dir")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, statT(t, fi).Nlink)
+
+	var paths []string
+
+	require.NoError(t, afero.Walk(fs, "/", func(path string, _ os.FileInfo, err error) error {
+		paths = append(paths, path)
+
+		return err
+	}))
+
+	assert.Equal(t, []string{"/", "/dir", "/dir/a.txt", "/dir/b.txt", "/dir/c"}, paths)
+}
+
+func TestInodeFs_Attributes(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	require.NoError(t, afero.WriteFile(fs, "file.txt", nil, 0o644))
+	require.NoError(t, fs.Chmod("file.txt", 0o600))
+	require.NoError(t, fs.Chown("file.txt", 1000, 100))
+
+	mtime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, fs.Chtimes("file.txt", mtime, mtime))
+
+	fi, err := fs.Stat("file.txt")
+	require.NoError(t, err)
+
+	assert.Equal(t, os.FileMode(0o600), fi.Mode())
+	assert.Equal(t, mtime, fi.ModTime())
+	assert.EqualValues(t, 1000, statT(t, fi).Uid)
+	assert.EqualValues(t, 100, statT(t, fi).Gid)
+	assert.Equal(t, "InodeFs", fs.Name())
+
+	require.ErrorIs(t, fs.Chmod("missing", 0o600), os.ErrNotExist)
+}
+
+func TestInodeFs_Symlink(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	require.NoError(t, fs.MkdirAll("data/sub", os.ModePerm))
+	require.NoError(t, afero.WriteFile(fs, "data/a.txt", []byte("hello"), 0o644))
+	require.NoError(t, fs.SymlinkIfPossible("a.txt", "data/relative"))
+	require.NoError(t, fs.SymlinkIfPossible("/data/a.txt", "absolute"))
+	require.NoError(t, fs.SymlinkIfPossible("../relative", "data/sub/parent"))
+	require.NoError(t, fs.SymlinkIfPossible("data/sub", "dir"))
+	require.NoError(t, fs.SymlinkIfPossible("dir/parent", "chain"))
+
+	testCases := []struct {
+		scenario string
+		path     string
+	}{
+		{scenario: "relative", path: "data/relative"},
+		{scenario: "absolute", path: "absolute"},
+		{scenario: "relative to the parent", path: "data/sub/parent"},
+		{scenario: "chain", path: "chain"},
+		{scenario: "link of a directory", path: "dir/../a.txt"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := afero.ReadFile(fs, tc.path)
+			require.NoError(t, err)
+			assert.Equal(t, "hello", string(data))
+
+			fi, err := fs.Stat(tc.path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o644), fi.Mode())
+		})
+	}
+}
+
+func TestInodeFs_LstatIfPossible(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("hello"), 0o644))
+	require.NoError(t, fs.SymlinkIfPossible("a.txt", "link"))
+	require.NoError(t, fs.SymlinkIfPossible("missing.txt", "dangling"))
+
+	fi, lstatCalled, err := fs.LstatIfPossible("link")
+	require.NoError(t, err)
+
+	assert.True(t, lstatCalled)
+	assert.Equal(t, "link", fi.Name())
+	assert.Equal(t, os.ModeSymlink|os.ModePerm, fi.Mode())
+	assert.EqualValues(t, len("a.txt"), fi.Size())
+	assert.EqualValues(t, syscall.S_IFLNK, statT(t, fi).Mode&syscall.S_IFMT)
+
+	fi, err = fs.Stat("link")
+	require.NoError(t, err)
+
+	assert.Equal(t, "link", fi.Name())
+	assert.Equal(t, os.FileMode(0o644), fi.Mode())
+	assert.EqualValues(t, len("hello"), fi.Size())
+
+	// A dangling link can be read, but not followed.
+	fi, _, err = fs.LstatIfPossible("dangling")
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink|os.ModePerm, fi.Mode())
+
+	target, err := fs.ReadlinkIfPossible("dangling")
+	require.NoError(t, err)
+	assert.Equal(t, "missing.txt", target)
+
+	_, err = fs.Stat("dangling")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestInodeFs_Symlink_Errors(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", nil, 0o644))
+	require.NoError(t, fs.SymlinkIfPossible("a.txt", "link"))
+	require.NoError(t, fs.SymlinkIfPossible("loop2", "loop1"))
+	require.NoError(t, fs.SymlinkIfPossible("loop1", "loop2"))
+	require.NoError(t, fs.SymlinkIfPossible("missing.txt", "dangling"))
+
+	testCases := []struct {
+		scenario      string
+		call          func() error
+		expectedError error
+	}{
+		{
+			scenario: "stat loop",
+			call: func() error {
+				_, err := fs.Stat("loop1")
+
+				return err
+			},
+			expectedError: syscall.ELOOP,
+		},
+		{
+			scenario: "open loop",
+			call: func() error {
+				_, err := fs.Open("loop1/a.txt")
+
+				return err
+			},
+			expectedError: syscall.ELOOP,
+		},
+		{
+			scenario: "open with O_NOFOLLOW",
+			call: func() error {
+				_, err := fs.OpenFile("link", os.O_RDONLY|syscall.O_NOFOLLOW, 0)
+
+				return err
+			},
+			expectedError: syscall.ELOOP,
+		},
+		{
+			scenario: "create exclusively through a dangling link",
+			call: func() error {
+				_, err := fs.OpenFile("dangling", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+
+				return err
+			},
+			expectedError: os.ErrExist,
+		},
+		{
+			scenario: "link as a directory",
+			call: func() error {
+				_, err := fs.Stat("link/b.txt")
+
+				return err
+			},
+			expectedError: syscall.ENOTDIR,
+		},
+		{
+			scenario: "readlink of a file",
+			call: func() error {
+				_, err := fs.ReadlinkIfPossible("a.txt")
+
+				return err
+			},
+			expectedError: syscall.EINVAL,
+		},
+		{
+			scenario: "readlink of a missing file",
+			call: func() error {
+				_, err := fs.ReadlinkIfPossible("missing.txt")
+
+				return err
+			},
+			expectedError: os.ErrNotExist,
+		},
+		{
+			scenario: "symlink exists",
+			call: func() error {
+				return fs.SymlinkIfPossible("b.txt", "a.txt")
+			},
+			expectedError: os.ErrExist,
+		},
+		{
+			scenario: "symlink to an empty target",
+			call: func() error {
+				return fs.SymlinkIfPossible("", "empty")
+			},
+			expectedError: os.ErrNotExist,
+		},
+		{
+			scenario: "mkdir over a dangling link",
+			call: func() error {
+				return fs.Mkdir("dangling", os.ModePerm)
+			},
+			expectedError: os.ErrExist,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, tc.call(), tc.expectedError)
+		})
+	}
+}
+
+func TestInodeFs_Symlink_Modify(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	require.NoError(t, fs.SymlinkIfPossible("a.txt", "link"))
+
+	// Creating through a dangling link creates the target.
+	require.NoError(t, afero.WriteFile(fs, "link", []byte("hello"), 0o644))
+
+	data, err := afero.ReadFile(fs, "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	// Rename and Remove act on the link itself.
+	require.NoError(t, fs.Rename("link", "renamed"))
+
+	target, err := fs.ReadlinkIfPossible("renamed")
+	require.NoError(t, err)
+	assert.Equal(t, "a.txt", target)
+
+	require.NoError(t, fs.Remove("renamed"))
+
+	_, _, err = fs.LstatIfPossible("renamed")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	exists, err := afero.Exists(fs, "a.txt")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestInodeFs_Symlink_Walk(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	require.NoError(t, fs.MkdirAll("data/dir", os.ModePerm))
+	require.NoError(t, afero.WriteFile(fs, "data/dir/a.txt", nil, 0o644))
+	require.NoError(t, fs.SymlinkIfPossible("dir", "data/link"))
+
+	var paths []string
+
+	// afero.Walk uses LstatIfPossible, so the link of the directory is not descended into.
+	err := afero.Walk(fs, "data", func(path string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+
+		paths = append(paths, path+" "+info.Mode().Type().String())
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	expected := []string{
+		"data d---------",
+		"data/dir d---------",
+		"data/dir/a.txt ----------",
+		"data/link L---------",
+	}
+
+	assert.Equal(t, expected, paths)
+}
+
+func TestInodeFs_FollowFunc(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewInodeFs()
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("hello"), 0o644))
+	require.NoError(t, fs.SymlinkIfPossible("a.txt", "first"))
+	require.NoError(t, fs.SymlinkIfPossible("first", "second"))
+
+	var hops []string
+
+	fs.FollowFunc = func(link, target string) error {
+		hops = append(hops, link+" -> "+target)
+
+		if link == "/first" {
+			return syscall.EIO
+		}
+
+		return nil
+	}
+
+	_, err := fs.Stat("second")
+	require.ErrorIs(t, err, syscall.EIO)
+
+	assert.Equal(t, []string{"/second -> first", "/first -> a.txt"}, hops)
+
+	// The link itself can still be read.
+	target, err := fs.ReadlinkIfPossible("first")
+	require.NoError(t, err)
+	assert.Equal(t, "a.txt", target)
+}
@@ -0,0 +1,8 @@
+//go:build linux || darwin
+
+package aferomock
+
+import "syscall"
+
+// oNoFollow is the flag of OpenFile that prevents following a symbolic link.
+const oNoFollow = syscall.O_NOFOLLOW
@@ -0,0 +1,375 @@
+package aferomock
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Op describes a call of a method of afero.Fs or afero.File that is intercepted.
+type Op struct {
+	// Method is the name of the method, for example Open or Write.
+	Method string
+	// File is the name of the file for the methods of afero.File, it is empty for the methods of afero.Fs.
+	File string
+	// Args are the arguments of the call.
+	Args []interface{}
+	// Results are the results of the call, they are set after the call and can be replaced by the hooks.
+	Results []interface{}
+}
+
+// Err returns the error of the call, if the last result is an error.
+func (op *Op) Err() error {
+	if len(op.Results) == 0 {
+		return nil
+	}
+
+	err, _ := op.Results[len(op.Results)-1].(error) //nolint: errcheck
+
+	return err
+}
+
+// Hooks are the hooks of Intercept. All the hooks are optional.
+type Hooks struct {
+	// Before is called before the call.
+	Before func(op *Op)
+	// Around is called instead of the call, it has to call next to make the call. It can skip the call by setting
+	// op.Results without calling next.
+	Around func(op *Op, next func())
+	// After is called after the call.
+	After func(op *Op)
+}
+
+// Intercept wraps an afero.Fs and calls the hooks for every call of its methods, and of the methods of the files that
+// it opens or creates.
+//
+// The returned callbacks implement afero.Symlinker, the calls of LstatIfPossible, SymlinkIfPossible and
+// ReadlinkIfPossible are intercepted too. When fs does not support symbolic links, LstatIfPossible falls back to Stat,
+// and SymlinkIfPossible and ReadlinkIfPossible return afero.ErrNoSymlink and afero.ErrNoReadlink.
+//
+//	fs := aferomock.Intercept(afero.NewMemMapFs(), aferomock.Hooks{
+//		After: func(op *aferomock.Op) {
+//			t.Logf("%s(%v) = %v", op.Method, op.Args, op.Results)
+//		},
+//	})
+func Intercept(base afero.Fs, hooks Hooks) SymlinkerCallbacks { //nolint: funlen
+	i := interceptor{hooks: hooks}
+
+	return SymlinkerCallbacks{
+		FsCallbacks: i.interceptFs(base),
+		LstatIfPossibleFunc: func(name string) (os.FileInfo, bool, error) {
+			r := i.call("LstatIfPossible", "", []interface{}{name}, func() []interface{} {
+				if l, ok := base.(afero.Lstater); ok {
+					fi, lstat, err := l.LstatIfPossible(name)
+
+					return []interface{}{fi, lstat, err}
+				}
+
+				fi, err := base.Stat(name)
+
+				return []interface{}{fi, false, err}
+			})
+
+			return result[os.FileInfo](r, 0), result[bool](r, 1), result[error](r, 2)
+		},
+		SymlinkIfPossibleFunc: func(oldname, newname string) error {
+			r := i.call("SymlinkIfPossible", "", []interface{}{oldname, newname}, func() []interface{} {
+				if l, ok := base.(afero.Linker); ok {
+					return []interface{}{l.SymlinkIfPossible(oldname, newname)}
+				}
+
+				return []interface{}{&os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: afero.ErrNoSymlink}}
+			})
+
+			return result[error](r, 0)
+		},
+		ReadlinkIfPossibleFunc: func(name string) (string, error) {
+			r := i.call("ReadlinkIfPossible", "", []interface{}{name}, func() []interface{} {
+				if l, ok := base.(afero.LinkReader); ok {
+					target, err := l.ReadlinkIfPossible(name)
+
+					return []interface{}{target, err}
+				}
+
+				return []interface{}{"", &os.PathError{Op: "readlink", Path: name, Err: afero.ErrNoReadlink}}
+			})
+
+			return result[string](r, 0), result[error](r, 1)
+		},
+	}
+}
+
+// InterceptFile wraps an afero.File and calls the hooks for every call of its methods.
+func InterceptFile(base afero.File, hooks Hooks) FileCallbacks {
+	i := interceptor{hooks: hooks}
+
+	return i.interceptFile(base.Name(), base)
+}
+
+type interceptor struct {
+	hooks Hooks
+}
+
+func (i interceptor) interceptFs(base afero.Fs) FsCallbacks { //nolint: funlen
+	return FsCallbacks{
+		ChmodFunc: func(name string, mode os.FileMode) error {
+			r := i.call("Chmod", "", []interface{}{name, mode}, func() []interface{} {
+				return []interface{}{base.Chmod(name, mode)}
+			})
+
+			return result[error](r, 0)
+		},
+		ChownFunc: func(name string, uid int, gid int) error {
+			r := i.call("Chown", "", []interface{}{name, uid, gid}, func() []interface{} {
+				return []interface{}{base.Chown(name, uid, gid)}
+			})
+
+			return result[error](r, 0)
+		},
+		ChtimesFunc: func(name string, atime time.Time, mtime time.Time) error {
+			r := i.call("Chtimes", "", []interface{}{name, atime, mtime}, func() []interface{} {
+				return []interface{}{base.Chtimes(name, atime, mtime)}
+			})
+
+			return result[error](r, 0)
+		},
+		CreateFunc: func(name string) (afero.File, error) {
+			r := i.call("Create", "", []interface{}{name}, func() []interface{} {
+				f, err := base.Create(name)
+
+				return []interface{}{f, err}
+			})
+
+			return i.file(name, result[afero.File](r, 0)), result[error](r, 1)
+		},
+		MkdirFunc: func(name string, perm os.FileMode) error {
+			r := i.call("Mkdir", "", []interface{}{name, perm}, func() []interface{} {
+				return []interface{}{base.Mkdir(name, perm)}
+			})
+
+			return result[error](r, 0)
+		},
+		MkdirAllFunc: func(path string, perm os.FileMode) error {
+			r := i.call("MkdirAll", "", []interface{}{path, perm}, func() []interface{} {
+				return []interface{}{base.MkdirAll(path, perm)}
+			})
+
+			return result[error](r, 0)
+		},
+		NameFunc: func() string {
+			r := i.call("Name", "", nil, func() []interface{} {
+				return []interface{}{base.Name()}
+			})
+
+			return result[string](r, 0)
+		},
+		OpenFunc: func(name string) (afero.File, error) {
+			r := i.call("Open", "", []interface{}{name}, func() []interface{} {
+				f, err := base.Open(name)
+
+				return []interface{}{f, err}
+			})
+
+			return i.file(name, result[afero.File](r, 0)), result[error](r, 1)
+		},
+		OpenFileFunc: func(name string, flag int, perm os.FileMode) (afero.File, error) {
+			r := i.call("OpenFile", "", []interface{}{name, flag, perm}, func() []interface{} {
+				f, err := base.OpenFile(name, flag, perm)
+
+				return []interface{}{f, err}
+			})
+
+			return i.file(name, result[afero.File](r, 0)), result[error](r, 1)
+		},
+		RemoveFunc: func(name string) error {
+			r := i.call("Remove", "", []interface{}{name}, func() []interface{} {
+				return []interface{}{base.Remove(name)}
+			})
+
+			return result[error](r, 0)
+		},
+		RemoveAllFunc: func(path string) error {
+			r := i.call("RemoveAll", "", []interface{}{path}, func() []interface{} {
+				return []interface{}{base.RemoveAll(path)}
+			})
+
+			return result[error](r, 0)
+		},
+		RenameFunc: func(oldname string, newname string) error {
+			r := i.call("Rename", "", []interface{}{oldname, newname}, func() []interface{} {
+				return []interface{}{base.Rename(oldname, newname)}
+			})
+
+			return result[error](r, 0)
+		},
+		StatFunc: func(name string) (os.FileInfo, error) {
+			r := i.call("Stat", "", []interface{}{name}, func() []interface{} {
+				fi, err := base.Stat(name)
+
+				return []interface{}{fi, err}
+			})
+
+			return result[os.FileInfo](r, 0), result[error](r, 1)
+		},
+	}
+}
+
+// call calls the hooks and the method, and returns the results.
+func (i interceptor) call(method, file string, args []interface{}, fn func() []interface{}) []interface{} {
+	op := &Op{Method: method, File: file, Args: args}
+
+	if i.hooks.Before != nil {
+		i.hooks.Before(op)
+	}
+
+	next := func() {
+		op.Results = fn()
+	}
+
+	if i.hooks.Around != nil {
+		i.hooks.Around(op, next)
+	} else {
+		next()
+	}
+
+	if i.hooks.After != nil {
+		i.hooks.After(op)
+	}
+
+	return op.Results
+}
+
+// file intercepts the calls of a file that is opened or created, a nil file is kept as is.
+func (i interceptor) file(name string, f afero.File) afero.File {
+	if f == nil {
+		return nil
+	}
+
+	return i.interceptFile(name, f)
+}
+
+func (i interceptor) interceptFile(name string, f afero.File) FileCallbacks { //nolint: funlen
+	return FileCallbacks{
+		CloseFunc: func() error {
+			r := i.call("Close", name, nil, func() []interface{} {
+				return []interface{}{f.Close()}
+			})
+
+			return result[error](r, 0)
+		},
+		NameFunc: func() string {
+			r := i.call("Name", name, nil, func() []interface{} {
+				return []interface{}{f.Name()}
+			})
+
+			return result[string](r, 0)
+		},
+		ReadFunc: func(p []byte) (int, error) {
+			r := i.call("Read", name, []interface{}{p}, func() []interface{} {
+				n, err := f.Read(p)
+
+				return []interface{}{n, err}
+			})
+
+			return result[int](r, 0), result[error](r, 1)
+		},
+		ReadAtFunc: func(p []byte, off int64) (int, error) {
+			r := i.call("ReadAt", name, []interface{}{p, off}, func() []interface{} {
+				n, err := f.ReadAt(p, off)
+
+				return []interface{}{n, err}
+			})
+
+			return result[int](r, 0), result[error](r, 1)
+		},
+		ReaddirFunc: func(count int) ([]os.FileInfo, error) {
+			r := i.call("Readdir", name, []interface{}{count}, func() []interface{} {
+				fis, err := f.Readdir(count)
+
+				return []interface{}{fis, err}
+			})
+
+			return result[[]os.FileInfo](r, 0), result[error](r, 1)
+		},
+		ReaddirnamesFunc: func(n int) ([]string, error) {
+			r := i.call("Readdirnames", name, []interface{}{n}, func() []interface{} {
+				names, err := f.Readdirnames(n)
+
+				return []interface{}{names, err}
+			})
+
+			return result[[]string](r, 0), result[error](r, 1)
+		},
+		SeekFunc: func(offset int64, whence int) (int64, error) {
+			r := i.call("Seek", name, []interface{}{offset, whence}, func() []interface{} {
+				ret, err := f.Seek(offset, whence)
+
+				return []interface{}{ret, err}
+			})
+
+			return result[int64](r, 0), result[error](r, 1)
+		},
+		StatFunc: func() (os.FileInfo, error) {
+			r := i.call("Stat", name, nil, func() []interface{} {
+				fi, err := f.Stat()
+
+				return []interface{}{fi, err}
+			})
+
+			return result[os.FileInfo](r, 0), result[error](r, 1)
+		},
+		SyncFunc: func() error {
+			r := i.call("Sync", name, nil, func() []interface{} {
+				return []interface{}{f.Sync()}
+			})
+
+			return result[error](r, 0)
+		},
+		TruncateFunc: func(size int64) error {
+			r := i.call("Truncate", name, []interface{}{size}, func() []interface{} {
+				return []interface{}{f.Truncate(size)}
+			})
+
+			return result[error](r, 0)
+		},
+		WriteFunc: func(p []byte) (int, error) {
+			r := i.call("Write", name, []interface{}{p}, func() []interface{} {
+				n, err := f.Write(p)
+
+				return []interface{}{n, err}
+			})
+
+			return result[int](r, 0), result[error](r, 1)
+		},
+		WriteAtFunc: func(p []byte, off int64) (int, error) {
+			r := i.call("WriteAt", name, []interface{}{p, off}, func() []interface{} {
+				n, err := f.WriteAt(p, off)
+
+				return []interface{}{n, err}
+			})
+
+			return result[int](r, 0), result[error](r, 1)
+		},
+		WriteStringFunc: func(s string) (int, error) {
+			r := i.call("WriteString", name, []interface{}{s}, func() []interface{} {
+				n, err := f.WriteString(s)
+
+				return []interface{}{n, err}
+			})
+
+			return result[int](r, 0), result[error](r, 1)
+		},
+	}
+}
+
+// result returns the result at the given index, or the zero value if it is missing or nil.
+func result[R any](results []interface{}, i int) R {
+	var r R
+
+	if i < len(results) {
+		r, _ = results[i].(R) //nolint: errcheck
+	}
+
+	return r
+}
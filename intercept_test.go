@@ -0,0 +1,212 @@
+package aferomock_test
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+type opLog struct {
+	mu  sync.Mutex
+	ops []string
+}
+
+func (l *opLog) add(s string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.ops = append(l.ops, s)
+}
+
+func TestIntercept_Fs(t *testing.T) {
+	t.Parallel()
+
+	var log opLog
+
+	fs := aferomock.Intercept(afero.NewMemMapFs(), aferomock.Hooks{
+		Before: func(op *aferomock.Op) {
+			log.add("before " + op.Method)
+		},
+		After: func(op *aferomock.Op) {
+			log.add("after " + op.Method)
+		},
+	})
+
+	require.NoError(t, fs.MkdirAll("data", os.ModePerm))
+	require.NoError(t, afero.WriteFile(fs, "data/file.txt", []byte("hello"), 0o644))
+
+	_, err := fs.Stat("unknown")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	expected := []string{
+		"before MkdirAll", "after MkdirAll",
+		"before OpenFile", "after OpenFile",
+		"before Write", "after Write",
+		"before Close", "after Close",
+		"before Stat", "after Stat",
+	}
+
+	assert.Equal(t, expected, log.ops)
+}
+
+func TestIntercept_Op(t *testing.T) {
+	t.Parallel()
+
+	var ops []aferomock.Op
+
+	fs := aferomock.Intercept(afero.NewMemMapFs(), aferomock.Hooks{
+		After: func(op *aferomock.Op) {
+			ops = append(ops, *op)
+		},
+	})
+
+	f, err := fs.Create("file.txt")
+	require.NoError(t, err)
+
+	n, err := f.WriteString("hello")
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	require.NoError(t, f.Close())
+
+	require.Len(t, ops, 3)
+
+	assert.Equal(t, "Create", ops[0].Method)
+	assert.Empty(t, ops[0].File)
+	assert.Equal(t, []interface{}{"file.txt"}, ops[0].Args)
+	assert.Len(t, ops[0].Results, 2)
+	assert.NoError(t, ops[0].Err())
+
+	assert.Equal(t, "WriteString", ops[1].Method)
+	assert.Equal(t, "file.txt", ops[1].File)
+	assert.Equal(t, []interface{}{"hello"}, ops[1].Args)
+	assert.Equal(t, []interface{}{5, nil}, ops[1].Results)
+
+	assert.Equal(t, "Close", ops[2].Method)
+	assert.NoError(t, ops[2].Err())
+}
+
+func TestIntercept_Around(t *testing.T) {
+	t.Parallel()
+
+	memFs := afero.NewMemMapFs()
+
+	require.NoError(t, memFs.Mkdir("data", os.ModePerm))
+
+	fs := aferomock.Intercept(memFs, aferomock.Hooks{
+		Around: func(op *aferomock.Op, next func()) {
+			if op.Method == "Remove" {
+				op.Results = []interface{}{errors.New("remove error")}
+
+				return
+			}
+
+			next()
+		},
+	})
+
+	require.EqualError(t, fs.Remove("data"), "remove error")
+
+	fi, err := fs.Stat("data")
+	require.NoError(t, err)
+	assert.True(t, fi.IsDir())
+}
+
+func TestIntercept_AfterReplacesResults(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.Intercept(afero.NewMemMapFs(), aferomock.Hooks{
+		After: func(op *aferomock.Op) {
+			if op.Method == "Read" {
+				op.Results = []interface{}{0, errors.New("read error")}
+			}
+		},
+	})
+
+	require.NoError(t, afero.WriteFile(fs, "file.txt", []byte("hello"), 0o644))
+
+	_, err := afero.ReadFile(fs, "file.txt")
+	require.EqualError(t, err, "read error")
+}
+
+func TestIntercept_OpenError(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.Intercept(afero.NewMemMapFs(), aferomock.Hooks{})
+
+	f, err := fs.Open("unknown")
+
+	require.ErrorIs(t, err, os.ErrNotExist)
+	assert.Nil(t, f)
+}
+
+func TestIntercept_Symlinker(t *testing.T) {
+	t.Parallel()
+
+	var log opLog
+
+	hooks := aferomock.Hooks{
+		After: func(op *aferomock.Op) {
+			log.add(op.Method)
+		},
+	}
+
+	fs := aferomock.Intercept(aferomock.NewInodeFs(), hooks)
+
+	require.NoError(t, afero.WriteFile(fs, "/file.txt", []byte("hello"), 0o644))
+	require.NoError(t, fs.SymlinkIfPossible("file.txt", "/link"))
+
+	target, err := fs.ReadlinkIfPossible("/link")
+	require.NoError(t, err)
+	assert.Equal(t, "file.txt", target)
+
+	fi, lstat, err := fs.LstatIfPossible("/link")
+	require.NoError(t, err)
+	assert.True(t, lstat)
+	assert.NotZero(t, fi.Mode()&os.ModeSymlink)
+
+	expected := []string{"OpenFile", "Write", "Close", "SymlinkIfPossible", "ReadlinkIfPossible", "LstatIfPossible"}
+
+	assert.Equal(t, expected, log.ops)
+
+	// A file system without symbolic links.
+	fs = aferomock.Intercept(afero.NewMemMapFs(), hooks)
+
+	require.NoError(t, afero.WriteFile(fs, "/file.txt", []byte("hello"), 0o644))
+	require.ErrorIs(t, fs.SymlinkIfPossible("file.txt", "/link"), afero.ErrNoSymlink)
+
+	_, err = fs.ReadlinkIfPossible("/file.txt")
+	require.ErrorIs(t, err, afero.ErrNoReadlink)
+
+	_, lstat, err = fs.LstatIfPossible("/file.txt")
+	require.NoError(t, err)
+	assert.False(t, lstat)
+}
+
+func TestInterceptFile(t *testing.T) {
+	t.Parallel()
+
+	var methods []string
+
+	f := aferomock.InterceptFile(aferomock.MockFile(func(f *aferomock.File) {
+		f.On("Name").Return("file.txt")
+		f.On("Sync").Return(errors.New("sync error"))
+	})(t), aferomock.Hooks{
+		Before: func(op *aferomock.Op) {
+			assert.Equal(t, "file.txt", op.File)
+
+			methods = append(methods, op.Method)
+		},
+	})
+
+	require.EqualError(t, f.Sync(), "sync error")
+
+	assert.Equal(t, []string{"Sync"}, methods)
+}
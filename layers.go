@@ -0,0 +1,102 @@
+package aferomock
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Layers is a composite afero.Fs whose layers are spied, so that the calls of the composite on each layer can be
+// asserted.
+//
+// Layers implements afero.Symlinker and delegates to the composite. When the composite does not support symbolic
+// links, such as afero.CacheOnReadFs, LstatIfPossible falls back to Stat, and SymlinkIfPossible and ReadlinkIfPossible
+// return afero.ErrNoSymlink and afero.ErrNoReadlink.
+//
+//	base := afero.NewMemMapFs()
+//	layers := aferomock.CacheOnReadLayers(base, afero.NewMemMapFs(), 0)
+//
+//	// ...
+//
+//	assert.Equal(t, 1, layers.Base.CallCount("Open", "config.yaml"))
+type Layers struct {
+	afero.Fs
+
+	// Base is the base layer.
+	Base *Spy
+	// Overlay is the layer on top of the base layer, it is nil for BasePathLayers and ReadOnlyLayers.
+	Overlay *Spy
+}
+
+var _ afero.Symlinker = (*Layers)(nil)
+
+// CopyOnWriteLayers creates an afero.CopyOnWriteFs over the base and the overlay layers.
+func CopyOnWriteLayers(base, overlay afero.Fs) *Layers {
+	l := &Layers{Base: NewSpy(base), Overlay: NewSpy(overlay)}
+	l.Fs = afero.NewCopyOnWriteFs(l.Base, l.Overlay)
+
+	return l
+}
+
+// CacheOnReadLayers creates an afero.CacheOnReadFs over the base and the cache layers.
+func CacheOnReadLayers(base, cache afero.Fs, cacheTime time.Duration) *Layers {
+	l := &Layers{Base: NewSpy(base), Overlay: NewSpy(cache)}
+	l.Fs = afero.NewCacheOnReadFs(l.Base, l.Overlay, cacheTime)
+
+	return l
+}
+
+// BasePathLayers creates an afero.BasePathFs over the base layer.
+func BasePathLayers(base afero.Fs, path string) *Layers {
+	l := &Layers{Base: NewSpy(base)}
+	l.Fs = afero.NewBasePathFs(l.Base, path)
+
+	return l
+}
+
+// ReadOnlyLayers creates an afero.ReadOnlyFs over the base layer.
+func ReadOnlyLayers(base afero.Fs) *Layers {
+	l := &Layers{Base: NewSpy(base)}
+	l.Fs = afero.NewReadOnlyFs(l.Base)
+
+	return l
+}
+
+// Reset removes the recorded calls of all the layers.
+func (l *Layers) Reset() {
+	l.Base.Reset()
+
+	if l.Overlay != nil {
+		l.Overlay.Reset()
+	}
+}
+
+// LstatIfPossible satisfies the afero.Lstater interface.
+func (l *Layers) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	if ls, ok := l.Fs.(afero.Lstater); ok {
+		return ls.LstatIfPossible(name)
+	}
+
+	fi, err := l.Stat(name)
+
+	return fi, false, err
+}
+
+// SymlinkIfPossible satisfies the afero.Linker interface.
+func (l *Layers) SymlinkIfPossible(oldname, newname string) error {
+	if ln, ok := l.Fs.(afero.Linker); ok {
+		return ln.SymlinkIfPossible(oldname, newname)
+	}
+
+	return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: afero.ErrNoSymlink}
+}
+
+// ReadlinkIfPossible satisfies the afero.LinkReader interface.
+func (l *Layers) ReadlinkIfPossible(name string) (string, error) {
+	if r, ok := l.Fs.(afero.LinkReader); ok {
+		return r.ReadlinkIfPossible(name)
+	}
+
+	return "", &os.PathError{Op: "readlink", Path: name, Err: afero.ErrNoReadlink}
+}
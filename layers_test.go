@@ -0,0 +1,150 @@
+package aferomock_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func newBaseFs(t *testing.T) afero.Fs {
+	t.Helper()
+
+	fs := afero.NewMemMapFs()
+
+	require.NoError(t, afero.WriteFile(fs, "/data/config.yaml", []byte("key: value"), 0o644))
+
+	return fs
+}
+
+func TestCopyOnWriteLayers(t *testing.T) {
+	t.Parallel()
+
+	layers := aferomock.CopyOnWriteLayers(newBaseFs(t), afero.NewMemMapFs())
+
+	require.NoError(t, afero.WriteFile(layers, "/data/config.yaml", []byte("key: other"), 0o644))
+	require.NoError(t, afero.WriteFile(layers, "/data/new.yaml", []byte("key: new"), 0o644))
+
+	data, err := afero.ReadFile(layers, "/data/config.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "key: other", string(data))
+
+	layers.Base.AssertNotCalled(t, "File.Write", mock.Anything)
+	layers.Base.AssertNotCalled(t, "Create", mock.Anything)
+	assert.Zero(t, layers.Base.CallCount("OpenFile", mock.Anything))
+
+	// The existing file is copied to the overlay before being written.
+	assert.Equal(t, 3, layers.Overlay.CallCount("File.Write", mock.Anything))
+	assert.Equal(t, 1, layers.Overlay.CallCount("File.Write", "/data/new.yaml"))
+}
+
+func TestCacheOnReadLayers(t *testing.T) {
+	t.Parallel()
+
+	layers := aferomock.CacheOnReadLayers(newBaseFs(t), afero.NewMemMapFs(), 0)
+
+	for range 3 {
+		data, err := afero.ReadFile(layers, "/data/config.yaml")
+		require.NoError(t, err)
+
+		assert.Equal(t, "key: value", string(data))
+	}
+
+	assert.Equal(t, 1, layers.Base.CallCount("Open", "/data/config.yaml"))
+	assert.Equal(t, 3, layers.Overlay.CallCount("Open", "/data/config.yaml"))
+
+	layers.Reset()
+
+	assert.Empty(t, layers.Base.Calls(""))
+	assert.Empty(t, layers.Overlay.Calls(""))
+}
+
+func TestBasePathLayers(t *testing.T) {
+	t.Parallel()
+
+	layers := aferomock.BasePathLayers(newBaseFs(t), "/data")
+
+	data, err := afero.ReadFile(layers, "config.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "key: value", string(data))
+	assert.Nil(t, layers.Overlay)
+
+	layers.Base.AssertCalled(t, "Open", filepath.FromSlash("/data/config.yaml"))
+}
+
+func TestReadOnlyLayers(t *testing.T) {
+	t.Parallel()
+
+	layers := aferomock.ReadOnlyLayers(newBaseFs(t))
+
+	err := layers.Remove("/data/config.yaml")
+	require.ErrorIs(t, err, os.ErrPermission)
+
+	_, err = layers.Stat("/data/config.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Stat"}, methods(layers.Base.Calls("")))
+
+	layers.Reset()
+
+	assert.Empty(t, layers.Base.Calls(""))
+}
+
+func TestLayers_Symlinker(t *testing.T) {
+	t.Parallel()
+
+	base := aferomock.NewInodeFs()
+
+	require.NoError(t, base.MkdirAll("/data", os.ModePerm))
+	require.NoError(t, afero.WriteFile(base, "/data/config.yaml", []byte("key: value"), 0o644))
+
+	layers := aferomock.BasePathLayers(base, "/data")
+
+	require.NoError(t, layers.SymlinkIfPossible("config.yaml", "link"))
+
+	// Like the production composite, afero.BasePathFs joins the target to its path.
+	target, err := layers.ReadlinkIfPossible("link")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/data/config.yaml"), target)
+
+	layers.Base.AssertCalled(t, "SymlinkIfPossible", filepath.FromSlash("/data/config.yaml"), filepath.FromSlash("/data/link"))
+
+	// The layers can be stacked.
+	stacked := aferomock.ReadOnlyLayers(layers)
+
+	fi, lstat, err := stacked.LstatIfPossible("link")
+	require.NoError(t, err)
+	assert.True(t, lstat)
+	assert.NotZero(t, fi.Mode()&os.ModeSymlink)
+
+	stacked.Base.AssertCalled(t, "LstatIfPossible", "link")
+
+	// The composite does not support symbolic links.
+	cached := aferomock.CacheOnReadLayers(base, afero.NewMemMapFs(), 0)
+
+	_, lstat, err = cached.LstatIfPossible("/data/config.yaml")
+	require.NoError(t, err)
+	assert.False(t, lstat)
+
+	require.ErrorIs(t, cached.SymlinkIfPossible("config.yaml", "/data/other"), afero.ErrNoSymlink)
+
+	_, err = cached.ReadlinkIfPossible("/data/link")
+	require.ErrorIs(t, err, afero.ErrNoReadlink)
+}
+
+func methods(calls []aferomock.Op) []string {
+	result := make([]string, len(calls))
+
+	for i, c := range calls {
+		result[i] = c.Method
+	}
+
+	return result
+}
@@ -0,0 +1,205 @@
+package aferomock
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+// LeakCheckFs wraps an afero.Fs and tracks the files opened by Open, OpenFile and Create. At the end of the test, it
+// fails the test and lists every file that was not closed, with the stack trace of where it was opened.
+//
+// It also fails the test when a file is closed twice or when a file is used after being closed.
+func LeakCheckFs(tb testing.TB, fs afero.Fs) FsCallbacks {
+	tb.Helper()
+
+	lc := &leakChecker{
+		tb:      tb,
+		handles: make(map[*leakHandle]struct{}),
+	}
+
+	tb.Cleanup(lc.check)
+
+	return OverrideFs(fs, FsCallbacks{
+		CreateFunc: func(name string) (afero.File, error) {
+			return lc.track(name)(fs.Create(name))
+		},
+		OpenFunc: func(name string) (afero.File, error) {
+			return lc.track(name)(fs.Open(name))
+		},
+		OpenFileFunc: func(name string, flag int, perm os.FileMode) (afero.File, error) {
+			return lc.track(name)(fs.OpenFile(name, flag, perm))
+		},
+	})
+}
+
+type leakHandle struct {
+	id         int
+	name       string
+	openStack  string
+	closed     bool
+	closeStack string
+}
+
+type leakChecker struct {
+	tb testing.TB
+
+	mu      sync.Mutex
+	nextID  int
+	handles map[*leakHandle]struct{}
+}
+
+// track wraps the opened file to track its handle. The handle is named after the opened path, because the name of the
+// file may not be available, such as on a File mock.
+func (lc *leakChecker) track(name string) func(afero.File, error) (afero.File, error) {
+	return func(f afero.File, err error) (afero.File, error) {
+		if err != nil {
+			return f, err
+		}
+
+		lc.mu.Lock()
+		defer lc.mu.Unlock()
+
+		lc.nextID++
+
+		h := &leakHandle{
+			id:        lc.nextID,
+			name:      name,
+			openStack: callerStack(),
+		}
+
+		lc.handles[h] = struct{}{}
+
+		return lc.wrap(h, f), nil
+	}
+}
+
+func (lc *leakChecker) wrap(h *leakHandle, f afero.File) FileCallbacks { //nolint: funlen
+	return OverrideFile(f, FileCallbacks{
+		CloseFunc: func() error {
+			lc.close(h)
+
+			return f.Close()
+		},
+		ReadFunc: func(p []byte) (int, error) {
+			lc.use(h, "Read")
+
+			return f.Read(p)
+		},
+		ReadAtFunc: func(p []byte, off int64) (int, error) {
+			lc.use(h, "ReadAt")
+
+			return f.ReadAt(p, off)
+		},
+		ReaddirFunc: func(count int) ([]fs.FileInfo, error) {
+			lc.use(h, "Readdir")
+
+			return f.Readdir(count)
+		},
+		ReaddirnamesFunc: func(n int) ([]string, error) {
+			lc.use(h, "Readdirnames")
+
+			return f.Readdirnames(n)
+		},
+		SeekFunc: func(offset int64, whence int) (int64, error) {
+			lc.use(h, "Seek")
+
+			return f.Seek(offset, whence)
+		},
+		StatFunc: func() (fs.FileInfo, error) {
+			lc.use(h, "Stat")
+
+			return f.Stat()
+		},
+		SyncFunc: func() error {
+			lc.use(h, "Sync")
+
+			return f.Sync()
+		},
+		TruncateFunc: func(size int64) error {
+			lc.use(h, "Truncate")
+
+			return f.Truncate(size)
+		},
+		WriteFunc: func(p []byte) (int, error) {
+			lc.use(h, "Write")
+
+			return f.Write(p)
+		},
+		WriteAtFunc: func(p []byte, off int64) (int, error) {
+			lc.use(h, "WriteAt")
+
+			return f.WriteAt(p, off)
+		},
+		WriteStringFunc: func(s string) (int, error) {
+			lc.use(h, "WriteString")
+
+			return f.WriteString(s)
+		},
+	})
+}
+
+func (lc *leakChecker) close(h *leakHandle) {
+	stack := callerStack()
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if h.closed {
+		lc.tb.Errorf("file %q is closed twice\nopened at:\n%sfirst closed at:\n%sclosed again at:\n%s",
+			h.name, h.openStack, h.closeStack, stack)
+
+		return
+	}
+
+	h.closed, h.closeStack = true, stack
+
+	delete(lc.handles, h)
+}
+
+func (lc *leakChecker) use(h *leakHandle, method string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if !h.closed {
+		return
+	}
+
+	lc.tb.Errorf("file %q is used after being closed: %s\nopened at:\n%sclosed at:\n%sused at:\n%s",
+		h.name, method, h.openStack, h.closeStack, callerStack())
+}
+
+func (lc *leakChecker) check() {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if len(lc.handles) == 0 {
+		return
+	}
+
+	handles := make([]*leakHandle, 0, len(lc.handles))
+
+	for h := range lc.handles {
+		handles = append(handles, h)
+	}
+
+	sort.Slice(handles, func(i, j int) bool {
+		return handles[i].id < handles[j].id
+	})
+
+	var sb strings.Builder
+
+	_, _ = fmt.Fprintf(&sb, "found %d unclosed file(s):\n", len(handles))
+
+	for _, h := range handles {
+		_, _ = fmt.Fprintf(&sb, "- %q opened at:\n%s", h.name, h.openStack)
+	}
+
+	lc.tb.Error(sb.String())
+}
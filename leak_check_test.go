@@ -0,0 +1,203 @@
+package aferomock_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestLeakCheckFs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		run            func(t *testing.T, fs afero.Fs)
+		expectedErrors []string
+	}{
+		{
+			scenario: "no leak",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				f, err := fs.Create("file.txt")
+				require.NoError(t, err)
+
+				_, err = f.WriteString("hello")
+				require.NoError(t, err)
+
+				require.NoError(t, f.Close())
+
+				f, err = fs.Open("file.txt")
+				require.NoError(t, err)
+				require.NoError(t, f.Close())
+			},
+		},
+		{
+			scenario: "failed to open",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				_, err := fs.Open("unknown.txt")
+				require.ErrorIs(t, err, os.ErrNotExist)
+			},
+		},
+		{
+			scenario: "leak",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				_, err := fs.Create("file.txt")
+				require.NoError(t, err)
+
+				_, err = fs.OpenFile("other.txt", os.O_CREATE|os.O_RDWR, 0o644)
+				require.NoError(t, err)
+			},
+			expectedErrors: []string{
+				`found 2 unclosed file(s):
+- "file.txt" opened at:
+	go.nhat.io/aferomock_test.TestLeakCheckFs`,
+				`- "other.txt" opened at:
+	go.nhat.io/aferomock_test.TestLeakCheckFs`,
+			},
+		},
+		{
+			scenario: "double close",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				f, err := fs.Create("file.txt")
+				require.NoError(t, err)
+
+				require.NoError(t, f.Close())
+
+				_ = f.Close() //nolint: errcheck
+			},
+			expectedErrors: []string{
+				`file "file.txt" is closed twice`,
+				"first closed at:\n\tgo.nhat.io/aferomock_test.TestLeakCheckFs",
+				"closed again at:\n\tgo.nhat.io/aferomock_test.TestLeakCheckFs",
+			},
+		},
+		{
+			scenario: "use after close",
+			run: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				f, err := fs.Create("file.txt")
+				require.NoError(t, err)
+
+				require.NoError(t, f.Close())
+
+				_, _ = f.Seek(0, 0) //nolint: errcheck
+			},
+			expectedErrors: []string{
+				`file "file.txt" is used after being closed: Seek`,
+				"used at:\n\tgo.nhat.io/aferomock_test.TestLeakCheckFs",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			ft := newFakeT(t)
+
+			tc.run(t, aferomock.LeakCheckFs(ft, afero.NewMemMapFs()))
+
+			ft.RunCleanup()
+
+			errs := ft.Errors()
+
+			if len(tc.expectedErrors) == 0 {
+				assert.Empty(t, errs)
+
+				return
+			}
+
+			require.Len(t, errs, 1)
+
+			for _, expected := range tc.expectedErrors {
+				assert.Contains(t, errs[0], expected)
+			}
+		})
+	}
+}
+
+func TestLeakCheckFs_ClosedWithoutCallerFrames(t *testing.T) {
+	t.Parallel()
+
+	closed := make(chan struct{})
+
+	base := aferomock.OverrideFs(afero.NewMemMapFs(), aferomock.FsCallbacks{})
+	base.CreateFunc = func(name string) (afero.File, error) {
+		f, err := afero.NewMemMapFs().Create(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return aferomock.OverrideFile(f, aferomock.FileCallbacks{
+			CloseFunc: func() error {
+				defer close(closed)
+
+				return f.Close()
+			},
+		}), nil
+	}
+
+	ft := newFakeT(t)
+	fs := aferomock.LeakCheckFs(ft, base)
+
+	f, err := fs.Create("file.txt")
+	require.NoError(t, err)
+
+	// The stack of the close has no frame outside of aferomock.
+	go f.Close() //nolint: errcheck
+
+	<-closed
+
+	_, _ = f.Seek(0, 0) //nolint: errcheck
+
+	ft.RunCleanup()
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], `file "file.txt" is used after being closed: Seek`)
+}
+
+func TestLeakCheckFs_Mocks(t *testing.T) {
+	t.Parallel()
+
+	closed := aferomock.MockFile(func(f *aferomock.File) {
+		f.On("Close").Return(nil).Once()
+	})(t)
+
+	leaked := aferomock.NopFile(t)
+
+	ft := newFakeT(t)
+
+	fs := aferomock.LeakCheckFs(ft, aferomock.MockFs(func(fs *aferomock.Fs) {
+		fs.On("Open", "a.txt").Return(closed, nil).Once()
+		fs.On("OpenFile", "b.txt", os.O_RDONLY, os.FileMode(0)).Return(leaked, nil).Once()
+	})(t))
+
+	f, err := fs.Open("a.txt")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	_, err = fs.OpenFile("b.txt", os.O_RDONLY, 0)
+	require.NoError(t, err)
+
+	ft.RunCleanup()
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "found 1 unclosed file(s):\n- \"b.txt\" opened at:\n\tgo.nhat.io/aferomock_test.TestLeakCheckFs_Mocks")
+}
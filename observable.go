@@ -0,0 +1,385 @@
+package aferomock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// EventOp is a set of operations that triggered an Event, like fsnotify.Op.
+type EventOp uint32
+
+// The operations of an Event.
+const (
+	// EventCreate is a new file or directory.
+	EventCreate EventOp = 1 << iota
+	// EventWrite is a write to a file, including a truncation.
+	EventWrite
+	// EventRemove is a removed file or directory.
+	EventRemove
+	// EventRename is a file or directory that is renamed, the new name gets an EventCreate.
+	EventRename
+	// EventChmod is a change of the attributes by Chmod, Chown or Chtimes.
+	EventChmod
+)
+
+var eventOpNames = []struct {
+	op   EventOp
+	name string
+}{
+	{op: EventCreate, name: "CREATE"},
+	{op: EventWrite, name: "WRITE"},
+	{op: EventRemove, name: "REMOVE"},
+	{op: EventRename, name: "RENAME"},
+	{op: EventChmod, name: "CHMOD"},
+}
+
+// Has reports whether the set contains the operation.
+func (o EventOp) Has(op EventOp) bool {
+	return o&op != 0
+}
+
+// String returns the names of the operations, separated by a pipe.
+func (o EventOp) String() string {
+	var names []string
+
+	for _, n := range eventOpNames {
+		if o.Has(n.op) {
+			names = append(names, n.name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "[no events]"
+	}
+
+	return strings.Join(names, "|")
+}
+
+// Event is a mutation of a file or a directory observed by ObservableFs.
+type Event struct {
+	// Name is the clean path of the file or the directory.
+	Name string
+	// Op is the operation that triggered the event.
+	Op EventOp
+}
+
+// String returns the operation and the path of the event.
+func (e Event) String() string {
+	return fmt.Sprintf("%-13s %q", e.Op.String(), e.Name)
+}
+
+// ObserveOption configures an ObservableFs.
+type ObserveOption func(o *ObservableFs)
+
+// WithEventBuffer sets the size of the buffer of the Events channel. The events are queued into the buffer from the
+// creation of the ObservableFs, even before Events is called, and a mutation only blocks when the buffer is full. By
+// default, the channel is unbuffered, so a mutation blocks until its events are received.
+func WithEventBuffer(size int) ObserveOption {
+	return func(o *ObservableFs) {
+		o.bufferSize = size
+	}
+}
+
+// WithEventHandler calls the handler for every event, in the goroutine of the mutation.
+func WithEventHandler(handler func(Event)) ObserveOption {
+	return func(o *ObservableFs) {
+		o.handlers = append(o.handlers, handler)
+	}
+}
+
+// WithEventPaths only emits the events of the paths, or of the files and the directories inside them.
+func WithEventPaths(paths ...string) ObserveOption {
+	return func(o *ObservableFs) {
+		for _, p := range paths {
+			o.paths = append(o.paths, filepath.Clean(p))
+		}
+	}
+}
+
+// WithEventFilter only emits the events for which the filter returns true.
+func WithEventFilter(filter func(Event) bool) ObserveOption {
+	return func(o *ObservableFs) {
+		o.filters = append(o.filters, filter)
+	}
+}
+
+// ObservableFs wraps an afero.Fs and emits an Event for every successful mutation, like fsnotify, so that the code that
+// watches files can be driven from tests. The events are emitted after the mutation, to the handlers set by
+// WithEventHandler and to the Events channel.
+//
+//   - Create, OpenFile with os.O_CREATE, Mkdir and MkdirAll emit EventCreate for every new file or directory.
+//   - Write, WriteAt, WriteString and Truncate on a file, and opening an existing file with os.O_TRUNC emit EventWrite.
+//   - Remove and RemoveAll emit EventRemove for the path when it exists.
+//   - Rename emits EventRename for the old name, and EventCreate for the new name.
+//   - Chmod, Chown and Chtimes emit EventChmod.
+//
+// The callbacks of the embedded FsCallbacks can be replaced to inject errors.
+//
+//	fs := aferomock.NewObservableFs(afero.NewMemMapFs(), aferomock.WithEventBuffer(10))
+//	defer fs.Close()
+//
+//	go watch(fs.Events())
+type ObservableFs struct {
+	FsCallbacks
+
+	bufferSize int
+	handlers   []func(Event)
+	paths      []string
+	filters    []func(Event) bool
+
+	mu      sync.Mutex
+	events  chan Event
+	done    chan struct{}
+	sending sync.WaitGroup
+	watched bool
+	closed  bool
+}
+
+// NewObservableFs wraps an afero.Fs to emit the events of its mutations.
+func NewObservableFs(fs afero.Fs, opts ...ObserveOption) *ObservableFs { //nolint: funlen
+	o := &ObservableFs{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	o.events = make(chan Event, o.bufferSize)
+	o.done = make(chan struct{})
+
+	// A buffered channel queues the events from the start, only an unbuffered channel waits for Events.
+	o.watched = o.bufferSize > 0
+
+	o.FsCallbacks = OverrideFs(fs, FsCallbacks{
+		ChmodFunc: func(name string, mode os.FileMode) error {
+			return o.emitIf(fs.Chmod(name, mode), EventChmod, name)
+		},
+		ChownFunc: func(name string, uid, gid int) error {
+			return o.emitIf(fs.Chown(name, uid, gid), EventChmod, name)
+		},
+		ChtimesFunc: func(name string, atime, mtime time.Time) error {
+			return o.emitIf(fs.Chtimes(name, atime, mtime), EventChmod, name)
+		},
+		CreateFunc: func(name string) (afero.File, error) {
+			exists := o.exists(fs, name)
+
+			f, err := fs.Create(name)
+
+			return o.opened(f, err, name, exists, true)
+		},
+		MkdirFunc: func(name string, perm os.FileMode) error {
+			return o.emitIf(fs.Mkdir(name, perm), EventCreate, name)
+		},
+		MkdirAllFunc: func(path string, perm os.FileMode) error {
+			var created []string
+
+			for p := filepath.Clean(path); p != "." && p != filepath.Dir(p) && !o.exists(fs, p); p = filepath.Dir(p) {
+				created = append([]string{p}, created...)
+			}
+
+			if err := fs.MkdirAll(path, perm); err != nil {
+				return err
+			}
+
+			for _, p := range created {
+				o.emit(EventCreate, p)
+			}
+
+			return nil
+		},
+		OpenFileFunc: func(name string, flag int, perm os.FileMode) (afero.File, error) {
+			exists := o.exists(fs, name)
+
+			f, err := fs.OpenFile(name, flag, perm)
+
+			return o.opened(f, err, name, exists, flag&os.O_TRUNC != 0 && flag&(os.O_WRONLY|os.O_RDWR) != 0)
+		},
+		OpenFunc: func(name string) (afero.File, error) {
+			f, err := fs.Open(name)
+
+			return o.opened(f, err, name, true, false)
+		},
+		RemoveFunc: func(name string) error {
+			return o.emitIf(fs.Remove(name), EventRemove, name)
+		},
+		RemoveAllFunc: func(path string) error {
+			exists := o.exists(fs, path)
+
+			if err := fs.RemoveAll(path); err != nil || !exists {
+				return err
+			}
+
+			o.emit(EventRemove, path)
+
+			return nil
+		},
+		RenameFunc: func(oldname, newname string) error {
+			if err := fs.Rename(oldname, newname); err != nil {
+				return err
+			}
+
+			o.emit(EventRename, oldname)
+			o.emit(EventCreate, newname)
+
+			return nil
+		},
+	})
+
+	return o
+}
+
+// Events returns the channel of the events. Without WithEventBuffer, the events are only sent to the channel once
+// Events is called, so that an ObservableFs that is only observed with WithEventHandler never blocks. With
+// WithEventBuffer, the events that happened before Events is called are in the buffer.
+func (o *ObservableFs) Events() <-chan Event {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.watched = true
+
+	return o.events
+}
+
+// Close closes the Events channel, the later events are only sent to the handlers. The mutations that are blocked
+// on sending an event are released, their events are dropped.
+func (o *ObservableFs) Close() {
+	o.mu.Lock()
+
+	if o.closed {
+		o.mu.Unlock()
+
+		return
+	}
+
+	o.closed = true
+
+	close(o.done)
+	o.mu.Unlock()
+
+	// The channel is closed once the pending senders are released.
+	o.sending.Wait()
+	close(o.events)
+}
+
+func (o *ObservableFs) exists(fs afero.Fs, name string) bool {
+	_, err := fs.Stat(name)
+
+	return err == nil
+}
+
+// emitIf emits the event if the mutation succeeded, and returns its error.
+func (o *ObservableFs) emitIf(err error, op EventOp, name string) error {
+	if err == nil {
+		o.emit(op, name)
+	}
+
+	return err
+}
+
+func (o *ObservableFs) emit(op EventOp, name string) {
+	e := Event{Name: filepath.Clean(name), Op: op}
+
+	if !o.accepts(e) {
+		return
+	}
+
+	for _, h := range o.handlers {
+		h(e)
+	}
+
+	o.mu.Lock()
+	send := o.watched && !o.closed
+
+	if send {
+		o.sending.Add(1)
+	}
+
+	o.mu.Unlock()
+
+	if !send {
+		return
+	}
+
+	defer o.sending.Done()
+
+	select {
+	case o.events <- e:
+	case <-o.done:
+	}
+}
+
+func (o *ObservableFs) accepts(e Event) bool {
+	if len(o.paths) > 0 {
+		matched := false
+
+		for _, p := range o.paths {
+			if isSubPath(p, e.Name) {
+				matched = true
+
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	for _, filter := range o.filters {
+		if !filter(e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// opened emits the events of a file that is opened, and wraps it to emit the events of its writes.
+func (o *ObservableFs) opened(f afero.File, err error, name string, existed, truncated bool) (afero.File, error) {
+	if err != nil {
+		return f, err
+	}
+
+	switch {
+	case !existed:
+		o.emit(EventCreate, name)
+
+	case truncated:
+		o.emit(EventWrite, name)
+	}
+
+	return o.file(name, f), nil
+}
+
+func (o *ObservableFs) file(name string, f afero.File) FileCallbacks {
+	return OverrideFile(f, FileCallbacks{
+		TruncateFunc: func(size int64) error {
+			return o.emitIf(f.Truncate(size), EventWrite, name)
+		},
+		WriteFunc: func(p []byte) (int, error) {
+			return o.written(name)(f.Write(p))
+		},
+		WriteAtFunc: func(p []byte, off int64) (int, error) {
+			return o.written(name)(f.WriteAt(p, off))
+		},
+		WriteStringFunc: func(s string) (int, error) {
+			return o.written(name)(f.WriteString(s))
+		},
+	})
+}
+
+// written emits EventWrite when some bytes are written.
+func (o *ObservableFs) written(name string) func(int, error) (int, error) {
+	return func(n int, err error) (int, error) {
+		if n > 0 {
+			o.emit(EventWrite, name)
+		}
+
+		return n, err
+	}
+}
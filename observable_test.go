@@ -0,0 +1,335 @@
+package aferomock_test
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+// eventRecorder records the events of an ObservableFs.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) handle(e aferomock.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, e.Op.String()+" "+e.Name)
+}
+
+func (r *eventRecorder) Events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.events...)
+}
+
+func TestObservableFs_Events(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		mutate         func(t *testing.T, fs afero.Fs)
+		expectedEvents []string
+	}{
+		{
+			scenario: "create",
+			mutate: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				f, err := fs.Create("/data/new.txt")
+				require.NoError(t, err)
+
+				_, err = f.WriteString("hello")
+				require.NoError(t, err)
+
+				_, err = f.WriteAt([]byte("j"), 0)
+				require.NoError(t, err)
+
+				require.NoError(t, f.Truncate(1))
+				require.NoError(t, f.Close())
+			},
+			expectedEvents: []string{
+				"CREATE /data/new.txt",
+				"WRITE /data/new.txt",
+				"WRITE /data/new.txt",
+				"WRITE /data/new.txt",
+			},
+		},
+		{
+			scenario: "truncate an existing file",
+			mutate: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				require.NoError(t, afero.WriteFile(fs, "/data/a.txt", []byte("world"), 0o644))
+			},
+			expectedEvents: []string{
+				"WRITE /data/a.txt",
+				"WRITE /data/a.txt",
+			},
+		},
+		{
+			scenario: "read",
+			mutate: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				_, err := afero.ReadFile(fs, "/data/a.txt")
+				require.NoError(t, err)
+
+				_, err = fs.OpenFile("/data/a.txt", os.O_RDONLY|os.O_TRUNC, 0)
+				require.NoError(t, err)
+			},
+		},
+		{
+			scenario: "mkdir all",
+			mutate: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				require.NoError(t, fs.MkdirAll("/data/b/c", os.ModePerm))
+				require.NoError(t, fs.Mkdir("/data/b/d", os.ModePerm))
+			},
+			expectedEvents: []string{
+				"CREATE /data/b",
+				"CREATE /data/b/c",
+				"CREATE /data/b/d",
+			},
+		},
+		{
+			scenario: "rename and remove",
+			mutate: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				require.NoError(t, fs.Rename("/data/a.txt", "/data/b.txt"))
+				require.NoError(t, fs.Remove("/data/b.txt"))
+				require.NoError(t, fs.RemoveAll("/data/missing"))
+				require.NoError(t, fs.RemoveAll("/data"))
+			},
+			expectedEvents: []string{
+				"RENAME /data/a.txt",
+				"CREATE /data/b.txt",
+				"REMOVE /data/b.txt",
+				"REMOVE /data",
+			},
+		},
+		{
+			scenario: "attributes",
+			mutate: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				require.NoError(t, fs.Chmod("/data/a.txt", 0o600))
+				require.NoError(t, fs.Chown("/data/a.txt", 1, 1))
+				require.NoError(t, fs.Chtimes("/data/a.txt", time.Now(), time.Now()))
+			},
+			expectedEvents: []string{
+				"CHMOD /data/a.txt",
+				"CHMOD /data/a.txt",
+				"CHMOD /data/a.txt",
+			},
+		},
+		{
+			scenario: "failures",
+			mutate: func(t *testing.T, fs afero.Fs) {
+				t.Helper()
+
+				require.Error(t, fs.Remove("/data/missing.txt"))
+				require.Error(t, fs.Rename("/data/missing.txt", "/data/b.txt"))
+				require.Error(t, fs.Chmod("/data/missing.txt", 0o600))
+
+				_, err := fs.Open("/data/missing.txt")
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			base := afero.NewMemMapFs()
+
+			require.NoError(t, afero.WriteFile(base, "/data/a.txt", []byte("hello"), 0o644))
+
+			r := &eventRecorder{}
+			fs := aferomock.NewObservableFs(base, aferomock.WithEventHandler(r.handle))
+
+			tc.mutate(t, fs)
+
+			assert.Equal(t, tc.expectedEvents, r.Events())
+		})
+	}
+}
+
+func TestObservableFs_Channel(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewObservableFs(afero.NewMemMapFs())
+	events := fs.Events()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer fs.Close()
+
+		assert.NoError(t, afero.WriteFile(fs, "config.yaml", []byte("key: value"), 0o644))
+	}()
+
+	var received []aferomock.Event
+
+	for e := range events {
+		received = append(received, e)
+	}
+
+	<-done
+
+	expected := []aferomock.Event{
+		{Name: "config.yaml", Op: aferomock.EventCreate},
+		{Name: "config.yaml", Op: aferomock.EventWrite},
+	}
+
+	assert.Equal(t, expected, received)
+
+	// The events after Close are not sent.
+	require.NoError(t, fs.Remove("config.yaml"))
+}
+
+func TestObservableFs_Buffer(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewObservableFs(afero.NewMemMapFs(), aferomock.WithEventBuffer(2))
+	events := fs.Events()
+
+	require.NoError(t, fs.Mkdir("data", os.ModePerm))
+	require.NoError(t, fs.Chmod("data", 0o700))
+
+	assert.Equal(t, aferomock.Event{Name: "data", Op: aferomock.EventCreate}, <-events)
+	assert.Equal(t, aferomock.Event{Name: "data", Op: aferomock.EventChmod}, <-events)
+}
+
+func TestObservableFs_BufferBeforeEvents(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewObservableFs(afero.NewMemMapFs(), aferomock.WithEventBuffer(10))
+
+	// The events are buffered before Events is called.
+	require.NoError(t, afero.WriteFile(fs, "config.yaml", []byte("key: value"), 0o644))
+
+	events := fs.Events()
+
+	assert.Equal(t, aferomock.Event{Name: "config.yaml", Op: aferomock.EventCreate}, <-events)
+	assert.Equal(t, aferomock.Event{Name: "config.yaml", Op: aferomock.EventWrite}, <-events)
+}
+
+func TestObservableFs_NotWatched(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewObservableFs(afero.NewMemMapFs())
+
+	// The mutations do not block when the events channel is not used.
+	require.NoError(t, afero.WriteFile(fs, "config.yaml", []byte("key: value"), 0o644))
+	require.NoError(t, fs.Remove("config.yaml"))
+}
+
+func TestObservableFs_Filters(t *testing.T) {
+	t.Parallel()
+
+	r := &eventRecorder{}
+
+	fs := aferomock.NewObservableFs(afero.NewMemMapFs(),
+		aferomock.WithEventHandler(r.handle),
+		aferomock.WithEventPaths("/config", "/data.txt"),
+		aferomock.WithEventFilter(func(e aferomock.Event) bool {
+			return !e.Op.Has(aferomock.EventChmod)
+		}),
+	)
+
+	require.NoError(t, fs.MkdirAll("/config/app", os.ModePerm))
+	require.NoError(t, afero.WriteFile(fs, "/config/app/app.yaml", nil, 0o644))
+	require.NoError(t, fs.Chmod("/config/app/app.yaml", 0o600))
+	require.NoError(t, afero.WriteFile(fs, "/data.txt", nil, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/data.txt.bak", nil, 0o644))
+	require.NoError(t, fs.Mkdir("/logs", os.ModePerm))
+
+	expected := []string{
+		"CREATE /config",
+		"CREATE /config/app",
+		"CREATE /config/app/app.yaml",
+		"CREATE /data.txt",
+	}
+
+	assert.Equal(t, expected, r.Events())
+}
+
+func TestObservableFs_Override(t *testing.T) {
+	t.Parallel()
+
+	r := &eventRecorder{}
+	fs := aferomock.NewObservableFs(afero.NewMemMapFs(), aferomock.WithEventHandler(r.handle))
+
+	fs.MkdirFunc = func(string, os.FileMode) error {
+		return errors.New("mkdir error")
+	}
+
+	require.EqualError(t, fs.Mkdir("data", os.ModePerm), "mkdir error")
+	assert.Empty(t, r.Events())
+}
+
+func TestEventOp_String(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		op       aferomock.EventOp
+		expected string
+	}{
+		{op: 0, expected: "[no events]"},
+		{op: aferomock.EventCreate, expected: "CREATE"},
+		{op: aferomock.EventWrite | aferomock.EventChmod, expected: "WRITE|CHMOD"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, tc.op.String())
+		})
+	}
+
+	assert.Equal(t, `RENAME        "data.txt"`, aferomock.Event{Name: "data.txt", Op: aferomock.EventRename}.String())
+}
+
+func TestObservableFs_CloseWhileSending(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewObservableFs(afero.NewMemMapFs())
+	events := fs.Events()
+
+	done := make(chan error)
+
+	go func() {
+		done <- fs.Mkdir("data", os.ModePerm)
+	}()
+
+	// Wait until the mutation is done and blocked on sending its event.
+	require.Eventually(t, func() bool {
+		_, err := fs.Stat("data")
+
+		return err == nil
+	}, time.Second, time.Millisecond)
+
+	fs.Close()
+
+	require.NoError(t, <-done)
+
+	_, ok := <-events
+	assert.False(t, ok)
+}
@@ -0,0 +1,121 @@
+package aferomock
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// OpenExpectation is an expectation of Fs.Open, Fs.OpenFile or Fs.Create that returns a File mock. The File mock is
+// created with the given testing.TB, its expectations are asserted at cleanup and its failures are reported with the
+// call that opened it. Close is expected on the file unless WithoutClose is used.
+type OpenExpectation struct {
+	*mock.Call
+
+	file      *File
+	closeCall *mock.Call
+}
+
+// ExpectOpen expects a call of Open and returns a File mock.
+//
+//	fs := aferomock.NewFs(t)
+//
+//	fs.ExpectOpen(t, "config.yaml").WithFile(func(f *aferomock.File) {
+//		f.On("Read", mock.Anything).Return(0, io.EOF)
+//	})
+func (fs *Fs) ExpectOpen(tb testing.TB, name interface{}) *OpenExpectation {
+	return fs.expectOpen(tb, "Open", name)
+}
+
+// ExpectOpenFile expects a call of OpenFile and returns a File mock.
+func (fs *Fs) ExpectOpenFile(tb testing.TB, name, flag, perm interface{}) *OpenExpectation {
+	return fs.expectOpen(tb, "OpenFile", name, flag, perm)
+}
+
+// ExpectCreate expects a call of Create and returns a File mock.
+func (fs *Fs) ExpectCreate(tb testing.TB, name interface{}) *OpenExpectation {
+	return fs.expectOpen(tb, "Create", name)
+}
+
+func (fs *Fs) expectOpen(tb testing.TB, method string, args ...interface{}) *OpenExpectation {
+	f := NewFile(&childT{TB: tb, prefix: fmt.Sprintf("file opened by %s", callString(method, args...))})
+
+	return &OpenExpectation{
+		Call:      fs.On(method, args...).Return(f, nil),
+		file:      f,
+		closeCall: f.On("Close").Return(nil).Once(),
+	}
+}
+
+// WithFile sets the expectations of the File mock. If the expectations include Close, the default expectation of
+// Close is removed.
+func (e *OpenExpectation) WithFile(mocks ...func(f *File)) *OpenExpectation {
+	for _, m := range mocks {
+		m(e.file)
+	}
+
+	if e.expectsOtherClose() {
+		return e.WithoutClose()
+	}
+
+	return e
+}
+
+// expectsOtherClose returns true if the File mock expects a Close call other than the default one.
+func (e *OpenExpectation) expectsOtherClose() bool {
+	mu := mockMutex(&e.file.Mock)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, c := range e.file.ExpectedCalls {
+		if c.Method == "Close" && c != e.closeCall {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithoutClose removes the default expectation of Close on the File mock.
+func (e *OpenExpectation) WithoutClose() *OpenExpectation {
+	if e.closeCall == nil {
+		return e
+	}
+
+	mu := mockMutex(&e.file.Mock)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	calls := make([]*mock.Call, 0, len(e.file.ExpectedCalls))
+
+	for _, c := range e.file.ExpectedCalls {
+		if c != e.closeCall {
+			calls = append(calls, c)
+		}
+	}
+
+	e.file.ExpectedCalls = calls
+	e.closeCall = nil
+
+	return e
+}
+
+// File returns the File mock.
+func (e *OpenExpectation) File() *File {
+	return e.file
+}
+
+// childT reports the failures of a child mock with the call that created it.
+type childT struct {
+	testing.TB
+
+	prefix string
+}
+
+func (t *childT) Errorf(format string, args ...interface{}) {
+	t.TB.Helper()
+	t.TB.Errorf("%s: "+format, append([]interface{}{t.prefix}, args...)...)
+}
@@ -0,0 +1,112 @@
+package aferomock_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestFs_ExpectOpen(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		fs.ExpectOpen(t, "config.yaml").WithFile(aferomock.ReadScript(
+			aferomock.ReadChunk("hello"),
+			aferomock.ReadErr(io.EOF),
+		))
+	})(t)
+
+	f, err := fs.Open("config.yaml")
+	require.NoError(t, err)
+
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+
+	assert.Equal(t, "hello", string(content))
+	require.NoError(t, f.Close())
+}
+
+func TestFs_ExpectCreate(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		fs.ExpectCreate(t, "data.txt").WithFile(func(f *aferomock.File) {
+			f.On("WriteString", "hello").Return(5, nil)
+			f.On("Close").Return(errors.New("close error"))
+		})
+	})(t)
+
+	f, err := fs.Create("data.txt")
+	require.NoError(t, err)
+
+	_, err = f.WriteString("hello")
+	require.NoError(t, err)
+
+	// The Close expectation of the file replaces the default one.
+	require.EqualError(t, f.Close(), "close error")
+}
+
+func TestFs_ExpectOpenFile_WithoutClose(t *testing.T) {
+	t.Parallel()
+
+	var file *aferomock.File
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		file = fs.ExpectOpenFile(t, "data.txt", os.O_RDONLY, os.FileMode(0)).
+			WithoutClose().
+			File()
+	})(t)
+
+	f, err := fs.OpenFile("data.txt", os.O_RDONLY, os.FileMode(0))
+	require.NoError(t, err)
+
+	assert.Same(t, file, f)
+}
+
+func TestFs_ExpectOpen_NewFs(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewFs(t)
+
+	fs.ExpectOpen(t, "config.yaml").WithFile(func(f *aferomock.File) {
+		f.On("Stat").Return(nil, os.ErrPermission).Once()
+	})
+
+	f, err := fs.Open("config.yaml")
+	require.NoError(t, err)
+
+	_, err = f.Stat()
+	require.ErrorIs(t, err, os.ErrPermission)
+
+	require.NoError(t, f.Close())
+}
+
+func TestFs_ExpectOpen_Unsatisfied(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		fs.ExpectOpen(ft, "config.yaml").WithFile(func(f *aferomock.File) {
+			f.On("Read", mock.Anything).Return(0, io.EOF)
+		})
+	})(ft)
+
+	_, err := fs.Open("config.yaml")
+	require.NoError(t, err)
+
+	ft.RunCleanup()
+
+	errs := ft.Errors()
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], `file opened by Open("config.yaml"): `)
+	assert.Contains(t, errs[0], "FAIL: 0 out of 2 expectation(s) were met.")
+}
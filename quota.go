@@ -0,0 +1,531 @@
+package aferomock
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/spf13/afero"
+)
+
+// QuotaOption configures the quotas of QuotaFs.
+type QuotaOption func(q *quota)
+
+// WithDirQuota limits the total bytes written under a directory.
+func WithDirQuota(dir string, limitBytes int64) QuotaOption {
+	return func(q *quota) {
+		q.rule(dir).bytesLimit = limitBytes
+	}
+}
+
+// WithInodeLimit limits the number of files and directories created through QuotaFs.
+func WithInodeLimit(limit int64) QuotaOption {
+	return func(q *quota) {
+		q.rule("").inodesLimit = limit
+	}
+}
+
+// WithDirInodeLimit limits the number of files and directories created under a directory.
+func WithDirInodeLimit(dir string, limit int64) QuotaOption {
+	return func(q *quota) {
+		q.rule(dir).inodesLimit = limit
+	}
+}
+
+// QuotaFs wraps an afero.Fs and returns syscall.ENOSPC when the bytes written through the files it opens exceed
+// limitBytes. A negative limitBytes means no global byte limit.
+//
+// Only the bytes written through QuotaFs are accounted, the existing content of the wrapped afero.Fs is not. A write is
+// only charged for the bytes past the end of the file, overwriting the existing bytes is free. Removing a file,
+// truncating it with File.Truncate or opening it with os.O_TRUNC releases the bytes that were charged for it.
+func QuotaFs(fs afero.Fs, limitBytes int64, opts ...QuotaOption) FsCallbacks {
+	q := &quota{
+		charged: make(map[string]int64),
+		inodes:  make(map[string]struct{}),
+	}
+
+	q.rule("").bytesLimit = limitBytes
+
+	for _, o := range opts {
+		o(q)
+	}
+
+	return OverrideFs(fs, FsCallbacks{
+		CreateFunc: func(name string) (afero.File, error) {
+			name = filepath.Clean(name)
+
+			created, err := q.createInode("open", name, fs)
+			if err != nil {
+				return nil, err
+			}
+
+			f, err := fs.Create(name)
+			if err != nil {
+				if created {
+					q.releaseInode(name)
+				}
+
+				return nil, err
+			}
+
+			q.truncate(name)
+
+			return q.file(name, f, false), nil
+		},
+		MkdirFunc: func(name string, perm os.FileMode) error {
+			name = filepath.Clean(name)
+
+			created, err := q.createInode("mkdir", name, fs)
+			if err != nil {
+				return err
+			}
+
+			if err := fs.Mkdir(name, perm); err != nil {
+				if created {
+					q.releaseInode(name)
+				}
+
+				return err
+			}
+
+			return nil
+		},
+		MkdirAllFunc: func(path string, perm os.FileMode) error {
+			var missing []string
+
+			for p := filepath.Clean(path); p != "." && p != filepath.Dir(p); p = filepath.Dir(p) {
+				if _, err := fs.Stat(p); err == nil {
+					break
+				}
+
+				missing = append([]string{p}, missing...)
+			}
+
+			var created []string
+
+			release := func() {
+				for _, p := range created {
+					q.releaseInode(p)
+				}
+			}
+
+			for _, p := range missing {
+				ok, err := q.createInode("mkdir", p, fs)
+				if err != nil {
+					release()
+
+					return err
+				}
+
+				if ok {
+					created = append(created, p)
+				}
+			}
+
+			if err := fs.MkdirAll(path, perm); err != nil {
+				release()
+
+				return err
+			}
+
+			return nil
+		},
+		OpenFunc: func(name string) (afero.File, error) {
+			f, err := fs.Open(name)
+			if err != nil {
+				return nil, err
+			}
+
+			return q.file(filepath.Clean(name), f, false), nil
+		},
+		OpenFileFunc: func(name string, flag int, perm os.FileMode) (afero.File, error) {
+			name = filepath.Clean(name)
+
+			var created bool
+
+			if flag&os.O_CREATE != 0 {
+				var err error
+
+				if created, err = q.createInode("open", name, fs); err != nil {
+					return nil, err
+				}
+			}
+
+			f, err := fs.OpenFile(name, flag, perm)
+			if err != nil {
+				if created {
+					q.releaseInode(name)
+				}
+
+				return nil, err
+			}
+
+			if flag&os.O_TRUNC != 0 {
+				q.truncate(name)
+			}
+
+			return q.file(name, f, flag&os.O_APPEND != 0), nil
+		},
+		RemoveFunc: func(name string) error {
+			name = filepath.Clean(name)
+
+			if err := fs.Remove(name); err != nil {
+				return err
+			}
+
+			q.remove(name)
+
+			return nil
+		},
+		RemoveAllFunc: func(path string) error {
+			path = filepath.Clean(path)
+
+			if err := fs.RemoveAll(path); err != nil {
+				return err
+			}
+
+			q.remove(path)
+
+			return nil
+		},
+		RenameFunc: func(oldname, newname string) error {
+			oldname = filepath.Clean(oldname)
+			newname = filepath.Clean(newname)
+
+			if err := fs.Rename(oldname, newname); err != nil {
+				return err
+			}
+
+			q.rename(oldname, newname)
+
+			return nil
+		},
+	})
+}
+
+type quotaRule struct {
+	dir         string
+	bytesLimit  int64
+	bytesUsed   int64
+	inodesLimit int64
+	inodesUsed  int64
+}
+
+func (r *quotaRule) contains(name string) bool {
+	return r.dir == "" || (name != r.dir && isSubPath(r.dir, name))
+}
+
+type quota struct {
+	mu sync.Mutex
+
+	rules   []*quotaRule
+	charged map[string]int64
+	inodes  map[string]struct{}
+}
+
+func (q *quota) rule(dir string) *quotaRule {
+	if dir != "" {
+		dir = filepath.Clean(dir)
+	}
+
+	for _, r := range q.rules {
+		if r.dir == dir {
+			return r
+		}
+	}
+
+	r := &quotaRule{dir: dir, bytesLimit: -1, inodesLimit: -1}
+	q.rules = append(q.rules, r)
+
+	return r
+}
+
+// available returns the number of bytes that can still be written to the file, or -1 if there is no limit.
+func (q *quota) available(name string) int64 {
+	avail := int64(-1)
+
+	for _, r := range q.rules {
+		if r.bytesLimit < 0 || !r.contains(name) {
+			continue
+		}
+
+		left := max(r.bytesLimit-r.bytesUsed, 0)
+
+		if avail < 0 || left < avail {
+			avail = left
+		}
+	}
+
+	return avail
+}
+
+func (q *quota) charge(name string, n int64) {
+	q.charged[name] += n
+
+	for _, r := range q.rules {
+		if r.contains(name) {
+			r.bytesUsed += n
+		}
+	}
+}
+
+func (q *quota) release(name string, n int64) {
+	n = min(n, q.charged[name])
+	if n <= 0 {
+		return
+	}
+
+	q.charge(name, -n)
+
+	if q.charged[name] == 0 {
+		delete(q.charged, name)
+	}
+}
+
+// reserve reserves up to n bytes for the file and returns the number of bytes granted.
+func (q *quota) reserve(name string, n int64) int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if avail := q.available(name); avail >= 0 && avail < n {
+		n = avail
+	}
+
+	q.charge(name, n)
+
+	return n
+}
+
+func (q *quota) unreserve(name string, n int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.release(name, n)
+}
+
+// truncate releases all the bytes charged for the file.
+func (q *quota) truncate(name string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.release(name, q.charged[name])
+}
+
+// createInode charges an inode for a file or directory that does not exist yet. It reports whether an inode was
+// charged.
+func (q *quota) createInode(op, name string, base afero.Fs) (bool, error) {
+	if _, err := base.Stat(name); err == nil {
+		return false, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.inodes[name]; ok {
+		return false, nil
+	}
+
+	for _, r := range q.rules {
+		if r.inodesLimit >= 0 && r.contains(name) && r.inodesUsed >= r.inodesLimit {
+			return false, &fs.PathError{Op: op, Path: name, Err: syscall.ENOSPC}
+		}
+	}
+
+	q.addInode(name)
+
+	return true, nil
+}
+
+func (q *quota) addInode(name string) {
+	q.inodes[name] = struct{}{}
+
+	for _, r := range q.rules {
+		if r.contains(name) {
+			r.inodesUsed++
+		}
+	}
+}
+
+func (q *quota) releaseInode(name string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.dropInode(name)
+}
+
+func (q *quota) dropInode(name string) {
+	if _, ok := q.inodes[name]; !ok {
+		return
+	}
+
+	delete(q.inodes, name)
+
+	for _, r := range q.rules {
+		if r.contains(name) {
+			r.inodesUsed--
+		}
+	}
+}
+
+func (q *quota) remove(path string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.removeLocked(path)
+}
+
+func (q *quota) rename(oldname, newname string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.removeLocked(newname)
+
+	charged := make(map[string]int64)
+
+	for name, n := range q.charged {
+		if isSubPath(oldname, name) {
+			charged[name] = n
+		}
+	}
+
+	for name, n := range charged {
+		q.release(name, n)
+		q.charge(newname+strings.TrimPrefix(name, oldname), n)
+	}
+
+	var inodes []string
+
+	for name := range q.inodes {
+		if isSubPath(oldname, name) {
+			inodes = append(inodes, name)
+		}
+	}
+
+	for _, name := range inodes {
+		q.dropInode(name)
+		q.addInode(newname + strings.TrimPrefix(name, oldname))
+	}
+}
+
+func (q *quota) removeLocked(path string) {
+	for name, n := range q.charged {
+		if isSubPath(path, name) {
+			q.release(name, n)
+		}
+	}
+
+	for name := range q.inodes {
+		if isSubPath(path, name) {
+			q.dropInode(name)
+		}
+	}
+}
+
+func (q *quota) file(name string, f afero.File, appending bool) FileCallbacks {
+	// write writes the bytes that fit in the quota at the offset, or at the end of the file when the offset is negative.
+	// Only the bytes past the end of the file are charged.
+	write := func(op string, p []byte, off int64, do func([]byte) (int, error)) (int, error) {
+		fi, err := f.Stat()
+		if err != nil {
+			return 0, err
+		}
+
+		// Read the size before writing because some implementations, such as afero.MemMapFs, return a live view.
+		size := fi.Size()
+
+		if off < 0 {
+			off = size
+		}
+
+		grow := max(off+int64(len(p))-size, 0)
+		granted := q.reserve(name, grow)
+		fit := max(int64(len(p))-(grow-granted), 0)
+
+		n, err := do(p[:fit])
+
+		q.unreserve(name, granted-max(off+int64(n)-size, 0))
+
+		if err == nil && fit < int64(len(p)) {
+			err = &fs.PathError{Op: op, Path: name, Err: syscall.ENOSPC}
+		}
+
+		return n, err
+	}
+
+	// offset returns the offset of the next write, or -1 when it is at the end of the file.
+	offset := func() int64 {
+		if appending {
+			return -1
+		}
+
+		off, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+
+		return off
+	}
+
+	return OverrideFile(f, FileCallbacks{
+		TruncateFunc: func(size int64) error {
+			fi, err := f.Stat()
+			if err != nil {
+				return err
+			}
+
+			// Read the size before truncating because some implementations, such as afero.MemMapFs, return a live view.
+			cur := fi.Size()
+
+			if grow := size - cur; grow > 0 {
+				if granted := q.reserve(name, grow); granted < grow {
+					q.unreserve(name, granted)
+
+					return &fs.PathError{Op: "truncate", Path: name, Err: syscall.ENOSPC}
+				}
+
+				if err := f.Truncate(size); err != nil {
+					q.unreserve(name, grow)
+
+					return err
+				}
+
+				return nil
+			}
+
+			if err := f.Truncate(size); err != nil {
+				return err
+			}
+
+			q.unreserve(name, cur-size)
+
+			return nil
+		},
+		WriteFunc: func(p []byte) (int, error) {
+			return write("write", p, offset(), f.Write)
+		},
+		WriteAtFunc: func(p []byte, off int64) (int, error) {
+			return write("write", p, off, func(p []byte) (int, error) {
+				return f.WriteAt(p, off)
+			})
+		},
+		WriteStringFunc: func(s string) (int, error) {
+			return write("write", []byte(s), offset(), func(p []byte) (int, error) {
+				return f.WriteString(string(p))
+			})
+		},
+	})
+}
+
+// isSubPath reports whether name is dir or is inside dir.
+func isSubPath(dir, name string) bool {
+	rel, err := filepath.Rel(dir, name)
+	if err != nil {
+		return false
+	}
+
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel))
+}
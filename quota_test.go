@@ -0,0 +1,348 @@
+package aferomock_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestQuotaFs_Write(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario        string
+		limit           int64
+		opts            []aferomock.QuotaOption
+		write           func(f afero.File) (int, error)
+		expectedWritten int
+		expectedContent string
+		expectedError   bool
+	}{
+		{
+			scenario: "write within limit",
+			limit:    10,
+			write: func(f afero.File) (int, error) {
+				return f.Write([]byte("hello"))
+			},
+			expectedWritten: 5,
+			expectedContent: "hello",
+		},
+		{
+			scenario: "write exceeds limit",
+			limit:    3,
+			write: func(f afero.File) (int, error) {
+				return f.Write([]byte("hello"))
+			},
+			expectedWritten: 3,
+			expectedContent: "hel",
+			expectedError:   true,
+		},
+		{
+			scenario: "write string exceeds limit",
+			limit:    4,
+			write: func(f afero.File) (int, error) {
+				return f.WriteString("hello")
+			},
+			expectedWritten: 4,
+			expectedContent: "hell",
+			expectedError:   true,
+		},
+		{
+			scenario: "write at exceeds limit",
+			limit:    2,
+			write: func(f afero.File) (int, error) {
+				return f.WriteAt([]byte("hello"), 0)
+			},
+			expectedWritten: 2,
+			expectedContent: "he",
+			expectedError:   true,
+		},
+		{
+			scenario: "no global limit",
+			limit:    -1,
+			write: func(f afero.File) (int, error) {
+				return f.Write([]byte("hello"))
+			},
+			expectedWritten: 5,
+			expectedContent: "hello",
+		},
+		{
+			scenario: "dir quota exceeds",
+			limit:    -1,
+			opts:     []aferomock.QuotaOption{aferomock.WithDirQuota("/data", 1)},
+			write: func(f afero.File) (int, error) {
+				return f.Write([]byte("hello"))
+			},
+			expectedWritten: 1,
+			expectedContent: "h",
+			expectedError:   true,
+		},
+		{
+			scenario: "other dir quota does not apply",
+			limit:    -1,
+			opts:     []aferomock.QuotaOption{aferomock.WithDirQuota("/tmp", 1)},
+			write: func(f afero.File) (int, error) {
+				return f.Write([]byte("hello"))
+			},
+			expectedWritten: 5,
+			expectedContent: "hello",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			base := afero.NewMemMapFs()
+			fs := aferomock.QuotaFs(base, tc.limit, tc.opts...)
+
+			f, err := fs.Create("/data/file.txt")
+			require.NoError(t, err)
+
+			n, err := tc.write(f)
+
+			assert.Equal(t, tc.expectedWritten, n)
+
+			if tc.expectedError {
+				assertENOSPC(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			require.NoError(t, f.Close())
+
+			content, err := afero.ReadFile(base, "/data/file.txt")
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedContent, string(content))
+		})
+	}
+}
+
+func TestQuotaFs_SharedAcrossHandles(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.QuotaFs(afero.NewMemMapFs(), 8)
+
+	f1, err := fs.Create("file1.txt")
+	require.NoError(t, err)
+
+	f2, err := fs.Create("file2.txt")
+	require.NoError(t, err)
+
+	_, err = f1.WriteString("12345")
+	require.NoError(t, err)
+
+	n, err := f2.WriteString("12345")
+
+	assert.Equal(t, 3, n)
+	assertENOSPC(t, err)
+
+	// Removing a file releases its bytes.
+	require.NoError(t, fs.Remove("file1.txt"))
+
+	n, err = f2.WriteString("12345")
+
+	assert.Equal(t, 5, n)
+	assert.NoError(t, err)
+}
+
+func TestQuotaFs_Truncate(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.QuotaFs(afero.NewMemMapFs(), 10)
+
+	f, err := fs.Create("file.txt")
+	require.NoError(t, err)
+
+	assertENOSPC(t, f.Truncate(11))
+	require.NoError(t, f.Truncate(10))
+
+	_, err = f.WriteAt([]byte("x"), 10)
+	assertENOSPC(t, err)
+
+	require.NoError(t, f.Truncate(4))
+
+	n, err := f.WriteAt([]byte("123456"), 4)
+
+	assert.Equal(t, 6, n)
+	assert.NoError(t, err)
+}
+
+func TestQuotaFs_Overwrite(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.QuotaFs(afero.NewMemMapFs(), 8)
+
+	f, err := fs.Create("file.txt")
+	require.NoError(t, err)
+
+	_, err = f.WriteString("hello")
+	require.NoError(t, err)
+
+	// Overwriting the existing bytes is not charged.
+	for range 3 {
+		n, err := f.WriteAt([]byte("hello"), 0)
+
+		assert.Equal(t, 5, n)
+		require.NoError(t, err)
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+
+	n, err := f.WriteString("HELLO!!!!")
+
+	assert.Equal(t, 8, n)
+	assertENOSPC(t, err)
+
+	// Truncate releases the bytes like os.O_TRUNC.
+	require.NoError(t, f.Truncate(0))
+
+	other, err := fs.Create("other.txt")
+	require.NoError(t, err)
+
+	n, err = other.WriteString("12345678")
+
+	assert.Equal(t, 8, n)
+	require.NoError(t, err)
+}
+
+func TestQuotaFs_Append(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.QuotaFs(afero.NewMemMapFs(), 8)
+
+	require.NoError(t, afero.WriteFile(fs, "file.txt", []byte("hello"), 0o644))
+
+	f, err := fs.OpenFile("file.txt", os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+
+	n, err := f.WriteString("12345")
+
+	assert.Equal(t, 3, n)
+	assertENOSPC(t, err)
+
+	require.NoError(t, f.Close())
+
+	f, err = fs.OpenFile("file.txt", os.O_WRONLY|os.O_TRUNC, 0)
+	require.NoError(t, err)
+
+	n, err = f.WriteString("12345678")
+
+	assert.Equal(t, 8, n)
+	require.NoError(t, err)
+}
+
+func TestQuotaFs_InodeLimit(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.QuotaFs(afero.NewMemMapFs(), -1,
+		aferomock.WithInodeLimit(3),
+		aferomock.WithDirInodeLimit("/logs", 1),
+	)
+
+	require.NoError(t, fs.Mkdir("/logs", os.ModePerm))
+
+	_, err := fs.Create("/logs/1.log")
+	require.NoError(t, err)
+
+	_, err = fs.Create("/logs/2.log")
+	assertENOSPC(t, err)
+
+	// Recreating an existing file does not use a new inode.
+	_, err = fs.Create("/logs/1.log")
+	require.NoError(t, err)
+
+	_, err = fs.OpenFile("/config.yaml", os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+
+	err = fs.Mkdir("/data", os.ModePerm)
+	assertENOSPC(t, err)
+
+	// Removing a file releases its inode.
+	require.NoError(t, fs.Remove("/config.yaml"))
+	require.NoError(t, fs.Mkdir("/data", os.ModePerm))
+}
+
+func TestQuotaFs_MkdirAll(t *testing.T) {
+	t.Parallel()
+
+	base := afero.NewMemMapFs()
+	fs := aferomock.QuotaFs(base, -1,
+		aferomock.WithInodeLimit(4),
+		aferomock.WithDirInodeLimit("/logs", 1),
+	)
+
+	// The directories are not created when one of them exceeds the limit.
+	assertENOSPC(t, fs.MkdirAll("/a/b/c/d/e", os.ModePerm))
+
+	exists, err := afero.DirExists(base, "/a")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, fs.MkdirAll("/a/b", os.ModePerm))
+
+	// The existing parents do not use a new inode.
+	require.NoError(t, fs.MkdirAll("/a/b/c", os.ModePerm))
+	require.NoError(t, fs.MkdirAll("/a/b/c", os.ModePerm))
+
+	require.NoError(t, fs.Mkdir("/logs", os.ModePerm))
+	assertENOSPC(t, fs.Mkdir("/x", os.ModePerm))
+
+	require.NoError(t, fs.RemoveAll("/a"))
+	require.NoError(t, fs.MkdirAll("/logs/2024", os.ModePerm))
+	assertENOSPC(t, fs.MkdirAll("/logs/2025/01", os.ModePerm))
+}
+
+func TestQuotaFs_Rename(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.QuotaFs(afero.NewMemMapFs(), -1, aferomock.WithDirQuota("/small", 2))
+
+	f, err := fs.Create("/big/file.txt")
+	require.NoError(t, err)
+
+	_, err = f.WriteString("hello")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.NoError(t, fs.Rename("/big/file.txt", "/small/file.txt"))
+
+	f, err = fs.OpenFile("/small/file.txt", os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+
+	_, err = f.WriteString("!")
+	assertENOSPC(t, err)
+}
+
+func TestQuotaFs_Upstream(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.QuotaFs(aferomock.MockFs(func(fs *aferomock.Fs) {
+		fs.On("Stat", "file.txt").Return(nil, os.ErrNotExist)
+		fs.On("Create", "file.txt").Return(nil, errors.New("create error"))
+	})(t), 0, aferomock.WithInodeLimit(1))
+
+	_, err := fs.Create("file.txt")
+
+	require.EqualError(t, err, "create error")
+}
+
+func assertENOSPC(t *testing.T, err error) {
+	t.Helper()
+
+	var pathErr *fs.PathError
+
+	require.ErrorAs(t, err, &pathErr)
+	assert.ErrorIs(t, err, syscall.ENOSPC)
+}
@@ -0,0 +1,113 @@
+package aferomock
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+)
+
+var errUnexpectedRead = errors.New("unexpected read")
+
+// ReadStep is a scripted response of File.Read.
+type ReadStep struct {
+	Data []byte
+	Err  error
+}
+
+// ReadChunk returns a ReadStep that reads the given data.
+func ReadChunk(data string) ReadStep {
+	return ReadStep{Data: []byte(data)}
+}
+
+// ReadErr returns a ReadStep that returns the given error, for example io.EOF.
+func ReadErr(err error) ReadStep {
+	return ReadStep{Err: err}
+}
+
+// ReadScript mocks File.Read with the given steps, in order. Each step is the response of one call, the data is copied
+// into the buffer of the caller. When the buffer is smaller than the data, the rest of the data is returned by the
+// next calls, and the error of the step is returned with the last part.
+//
+// The File mock fails the test when Read is called more than scripted, or, at cleanup, when not all the steps are
+// read.
+//
+//	f := aferomock.MockFile(aferomock.ReadScript(
+//		aferomock.ReadChunk("abc"),
+//		aferomock.ReadChunk("def"),
+//		aferomock.ReadErr(io.EOF),
+//	))(t)
+func ReadScript(steps ...ReadStep) func(f *File) {
+	return func(f *File) {
+		s := &readScript{steps: steps}
+
+		s.onSplit = func() {
+			f.On("Read", mock.Anything).Once().Return(s.read)
+		}
+
+		for range steps {
+			f.On("Read", mock.Anything).Once().Return(s.read)
+		}
+	}
+}
+
+// ReadScriptFunc returns a FileCallbacks.ReadFunc that responds with the given steps, in order. See ReadScript.
+//
+// It fails the test when it is called more than scripted, or, at cleanup, when not all the steps are read.
+func ReadScriptFunc(tb testing.TB, steps ...ReadStep) func(p []byte) (int, error) {
+	tb.Helper()
+
+	s := &readScript{steps: steps}
+
+	tb.Cleanup(func() {
+		if left := s.left(); left > 0 {
+			tb.Errorf("read script: %d of %d step(s) not read", left, len(steps))
+		}
+	})
+
+	return func(p []byte) (int, error) {
+		if s.left() == 0 {
+			tb.Errorf("read script: unexpected Read call, all %d step(s) are read", len(steps))
+
+			return 0, errUnexpectedRead
+		}
+
+		return s.read(p)
+	}
+}
+
+type readScript struct {
+	mu      sync.Mutex
+	steps   []ReadStep
+	onSplit func()
+}
+
+func (s *readScript) left() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.steps)
+}
+
+func (s *readScript) read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	step := s.steps[0]
+	s.steps = s.steps[1:]
+
+	n := copy(p, step.Data)
+
+	if n < len(step.Data) {
+		s.steps = append([]ReadStep{{Data: step.Data[n:], Err: step.Err}}, s.steps...)
+
+		if s.onSplit != nil {
+			s.onSplit()
+		}
+
+		return n, nil
+	}
+
+	return n, step.Err
+}
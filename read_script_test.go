@@ -0,0 +1,156 @@
+package aferomock_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestReadScript(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario        string
+		steps           []aferomock.ReadStep
+		bufSize         int
+		expectedContent string
+		expectedError   error
+	}{
+		{
+			scenario: "chunks and eof",
+			steps: []aferomock.ReadStep{
+				aferomock.ReadChunk("abc"),
+				aferomock.ReadChunk("def"),
+				aferomock.ReadErr(io.EOF),
+			},
+			bufSize:         10,
+			expectedContent: "abcdef",
+		},
+		{
+			scenario: "chunk larger than buffer",
+			steps: []aferomock.ReadStep{
+				aferomock.ReadChunk("abcdef"),
+				aferomock.ReadErr(io.EOF),
+			},
+			bufSize:         4,
+			expectedContent: "abcdef",
+		},
+		{
+			scenario: "data with eof",
+			steps: []aferomock.ReadStep{
+				{Data: []byte("abcdef"), Err: io.EOF},
+			},
+			bufSize:         4,
+			expectedContent: "abcdef",
+		},
+		{
+			scenario: "error in the middle",
+			steps: []aferomock.ReadStep{
+				aferomock.ReadChunk("abc"),
+				aferomock.ReadErr(errors.New("read error")),
+			},
+			bufSize:         10,
+			expectedContent: "abc",
+			expectedError:   errors.New("read error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			for name, r := range map[string]io.Reader{
+				"mock":      aferomock.MockFile(aferomock.ReadScript(tc.steps...))(t),
+				"callbacks": aferomock.FileCallbacks{ReadFunc: aferomock.ReadScriptFunc(t, tc.steps...)},
+			} {
+				content, err := readAll(r, tc.bufSize)
+
+				assert.Equal(t, tc.expectedContent, string(content), name)
+				assert.Equal(t, tc.expectedError, err, name)
+			}
+		})
+	}
+}
+
+func TestReadScript_TooManyReads(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+	f := aferomock.MockFile(aferomock.ReadScript(aferomock.ReadChunk("abc")))(ft)
+
+	n, err := f.Read(make([]byte, 10))
+
+	assert.Equal(t, 3, n)
+	require.NoError(t, err)
+
+	assert.Panics(t, func() {
+		_, _ = f.Read(make([]byte, 10)) //nolint: errcheck
+	})
+
+	require.Len(t, ft.Errors(), 1)
+	assert.Contains(t, ft.Errors()[0], "The method has been called over 1 times.")
+}
+
+func TestReadScriptFunc_Fail(t *testing.T) {
+	t.Parallel()
+
+	t.Run("too many reads", func(t *testing.T) {
+		t.Parallel()
+
+		ft := newFakeT(t)
+		read := aferomock.ReadScriptFunc(ft, aferomock.ReadChunk("abc"))
+
+		n, err := read(make([]byte, 10))
+
+		assert.Equal(t, 3, n)
+		require.NoError(t, err)
+
+		n, err = read(make([]byte, 10))
+
+		assert.Equal(t, 0, n)
+		require.Error(t, err)
+
+		ft.RunCleanup()
+
+		assert.Equal(t, []string{"read script: unexpected Read call, all 1 step(s) are read"}, ft.Errors())
+	})
+
+	t.Run("not all steps are read", func(t *testing.T) {
+		t.Parallel()
+
+		ft := newFakeT(t)
+		read := aferomock.ReadScriptFunc(ft, aferomock.ReadChunk("abc"), aferomock.ReadErr(io.EOF))
+
+		_, err := read(make([]byte, 10))
+		require.NoError(t, err)
+
+		ft.RunCleanup()
+
+		assert.Equal(t, []string{"read script: 1 of 2 step(s) not read"}, ft.Errors())
+	})
+}
+
+// readAll reads until an error occurs, with a buffer of the given size.
+func readAll(r io.Reader, bufSize int) ([]byte, error) {
+	var result []byte
+
+	buf := make([]byte, bufSize)
+
+	for {
+		n, err := r.Read(buf)
+		result = append(result, buf[:n]...)
+
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+
+		if err != nil {
+			return result, err
+		}
+	}
+}
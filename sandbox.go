@@ -0,0 +1,194 @@
+package aferomock
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// SandboxFs wraps an afero.Fs and fails the test when a method receives a path that resolves outside root after
+// cleaning, such as "root/../etc/passwd". The paths that are checked are the paths of every method of afero.Fs, both
+// names of Rename, and the name and the target of SymlinkIfPossible, a relative target being resolved from the
+// directory of the link. Relative paths are resolved from the working directory.
+//
+// The failure message contains the offending path and the stack trace of the call. The call is not made and returns a
+// *fs.PathError with fs.ErrPermission. Unlike afero.BasePathFs, the paths are not clamped into root, so an escape is
+// detected instead of being silently fixed.
+//
+// The returned callbacks implement afero.Symlinker. When fs does not support symbolic links, LstatIfPossible falls
+// back to Stat, and SymlinkIfPossible and ReadlinkIfPossible return afero.ErrNoSymlink and afero.ErrNoReadlink.
+//
+//	fs := aferomock.SandboxFs(t, "/data", afero.NewMemMapFs())
+//
+//	_, err := fs.Open(filepath.Join("/data", userInput))
+func SandboxFs(tb testing.TB, root string, fs afero.Fs) SymlinkerCallbacks { //nolint: funlen
+	tb.Helper()
+
+	s := &sandbox{tb: tb, root: absPath(root)}
+
+	return SymlinkerCallbacks{
+		FsCallbacks: OverrideFs(fs, FsCallbacks{
+			ChmodFunc: func(name string, mode os.FileMode) error {
+				if err := s.check("Chmod", []string{name}, name, mode); err != nil {
+					return err
+				}
+
+				return fs.Chmod(name, mode)
+			},
+			ChownFunc: func(name string, uid, gid int) error {
+				if err := s.check("Chown", []string{name}, name, uid, gid); err != nil {
+					return err
+				}
+
+				return fs.Chown(name, uid, gid)
+			},
+			ChtimesFunc: func(name string, atime, mtime time.Time) error {
+				if err := s.check("Chtimes", []string{name}, name, atime, mtime); err != nil {
+					return err
+				}
+
+				return fs.Chtimes(name, atime, mtime)
+			},
+			CreateFunc: func(name string) (afero.File, error) {
+				if err := s.check("Create", []string{name}, name); err != nil {
+					return nil, err
+				}
+
+				return fs.Create(name)
+			},
+			MkdirFunc: func(name string, perm os.FileMode) error {
+				if err := s.check("Mkdir", []string{name}, name, perm); err != nil {
+					return err
+				}
+
+				return fs.Mkdir(name, perm)
+			},
+			MkdirAllFunc: func(path string, perm os.FileMode) error {
+				if err := s.check("MkdirAll", []string{path}, path, perm); err != nil {
+					return err
+				}
+
+				return fs.MkdirAll(path, perm)
+			},
+			OpenFunc: func(name string) (afero.File, error) {
+				if err := s.check("Open", []string{name}, name); err != nil {
+					return nil, err
+				}
+
+				return fs.Open(name)
+			},
+			OpenFileFunc: func(name string, flag int, perm os.FileMode) (afero.File, error) {
+				if err := s.check("OpenFile", []string{name}, name, flag, perm); err != nil {
+					return nil, err
+				}
+
+				return fs.OpenFile(name, flag, perm)
+			},
+			RemoveFunc: func(name string) error {
+				if err := s.check("Remove", []string{name}, name); err != nil {
+					return err
+				}
+
+				return fs.Remove(name)
+			},
+			RemoveAllFunc: func(path string) error {
+				if err := s.check("RemoveAll", []string{path}, path); err != nil {
+					return err
+				}
+
+				return fs.RemoveAll(path)
+			},
+			RenameFunc: func(oldname, newname string) error {
+				if err := s.check("Rename", []string{oldname, newname}, oldname, newname); err != nil {
+					return err
+				}
+
+				return fs.Rename(oldname, newname)
+			},
+			StatFunc: func(name string) (os.FileInfo, error) {
+				if err := s.check("Stat", []string{name}, name); err != nil {
+					return nil, err
+				}
+
+				return fs.Stat(name)
+			},
+		}),
+		LstatIfPossibleFunc: func(name string) (os.FileInfo, bool, error) {
+			if err := s.check("LstatIfPossible", []string{name}, name); err != nil {
+				return nil, false, err
+			}
+
+			if l, ok := fs.(afero.Lstater); ok {
+				return l.LstatIfPossible(name)
+			}
+
+			fi, err := fs.Stat(name)
+
+			return fi, false, err
+		},
+		SymlinkIfPossibleFunc: func(oldname, newname string) error {
+			target := oldname
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(newname), target)
+			}
+
+			if err := s.check("SymlinkIfPossible", []string{newname, target}, oldname, newname); err != nil {
+				return err
+			}
+
+			if l, ok := fs.(afero.Linker); ok {
+				return l.SymlinkIfPossible(oldname, newname)
+			}
+
+			return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: afero.ErrNoSymlink}
+		},
+		ReadlinkIfPossibleFunc: func(name string) (string, error) {
+			if err := s.check("ReadlinkIfPossible", []string{name}, name); err != nil {
+				return "", err
+			}
+
+			if l, ok := fs.(afero.LinkReader); ok {
+				return l.ReadlinkIfPossible(name)
+			}
+
+			return "", &os.PathError{Op: "readlink", Path: name, Err: afero.ErrNoReadlink}
+		},
+	}
+}
+
+type sandbox struct {
+	tb   testing.TB
+	root string
+}
+
+// check fails the test and returns an error when one of the paths is outside the root.
+func (s *sandbox) check(method string, paths []string, args ...interface{}) error {
+	for _, p := range paths {
+		if isSubPath(s.root, absPath(p)) {
+			continue
+		}
+
+		s.tb.Errorf("aferomock: %s escapes the sandbox %q with path %q\n%s",
+			callString(method, args...), s.root, p, callerStack())
+
+		op := strings.ToLower(strings.TrimSuffix(method, "IfPossible"))
+
+		return &os.PathError{Op: op, Path: p, Err: os.ErrPermission}
+	}
+
+	return nil
+}
+
+// absPath returns the absolute and clean form of the path, it is only cleaned if the working directory is unknown.
+func absPath(name string) string {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return filepath.Clean(name)
+	}
+
+	return abs
+}
@@ -0,0 +1,149 @@
+package aferomock_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestSandboxFs_Inside(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+	fs := aferomock.SandboxFs(ft, "/data", aferomock.NewInodeFs())
+
+	require.NoError(t, fs.MkdirAll("/data/dir", os.ModePerm))
+	require.NoError(t, afero.WriteFile(fs, "/data/dir/a.txt", []byte("hello"), 0o644))
+	require.NoError(t, fs.Rename("/data/dir/a.txt", "/data/b.txt"))
+	require.NoError(t, fs.Chtimes("/data/b.txt", time.Now(), time.Now()))
+	require.NoError(t, fs.SymlinkIfPossible("../b.txt", "/data/dir/link"))
+	require.NoError(t, fs.SymlinkIfPossible("/data/b.txt", "/data/abs"))
+
+	data, err := afero.ReadFile(fs, "/data/dir/../dir/link")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	target, err := fs.ReadlinkIfPossible("/data/dir/link")
+	require.NoError(t, err)
+	assert.Equal(t, "../b.txt", target)
+
+	_, lstatCalled, err := fs.LstatIfPossible("/data/abs")
+	require.NoError(t, err)
+	assert.True(t, lstatCalled)
+
+	require.NoError(t, fs.RemoveAll("/data"))
+
+	assert.Empty(t, ft.Errors())
+}
+
+func TestSandboxFs_Escape(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario        string
+		call            func(fs aferomock.SymlinkerCallbacks) error
+		expectedMessage string
+	}{
+		{
+			scenario: "open with dot dot",
+			call: func(fs aferomock.SymlinkerCallbacks) error {
+				_, err := fs.Open("/data/../etc/passwd")
+
+				return err
+			},
+			expectedMessage: `aferomock: Open("/data/../etc/passwd") escapes the sandbox "/data" with path "/data/../etc/passwd"`,
+		},
+		{
+			scenario: "sibling with the same prefix",
+			call: func(fs aferomock.SymlinkerCallbacks) error {
+				return fs.Mkdir("/database", os.ModePerm)
+			},
+			expectedMessage: `aferomock: Mkdir("/database", -rwxrwxrwx) escapes the sandbox "/data" with path "/database"`,
+		},
+		{
+			scenario: "rename target",
+			call: func(fs aferomock.SymlinkerCallbacks) error {
+				return fs.Rename("/data/a.txt", "/data/../tmp/a.txt")
+			},
+			expectedMessage: `aferomock: Rename("/data/a.txt", "/data/../tmp/a.txt") escapes the sandbox "/data" with path "/data/../tmp/a.txt"`,
+		},
+		{
+			scenario: "relative symlink target",
+			call: func(fs aferomock.SymlinkerCallbacks) error {
+				return fs.SymlinkIfPossible("../../etc/passwd", "/data/dir/link")
+			},
+			expectedMessage: `aferomock: SymlinkIfPossible("../../etc/passwd", "/data/dir/link") escapes the sandbox "/data" with path "/etc/passwd"`,
+		},
+		{
+			scenario: "absolute symlink target",
+			call: func(fs aferomock.SymlinkerCallbacks) error {
+				return fs.SymlinkIfPossible("/etc/passwd", "/data/link")
+			},
+			expectedMessage: `aferomock: SymlinkIfPossible("/etc/passwd", "/data/link") escapes the sandbox "/data" with path "/etc/passwd"`,
+		},
+		{
+			scenario: "lstat",
+			call: func(fs aferomock.SymlinkerCallbacks) error {
+				_, _, err := fs.LstatIfPossible("/")
+
+				return err
+			},
+			expectedMessage: `aferomock: LstatIfPossible("/") escapes the sandbox "/data" with path "/"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			base := aferomock.NewInodeFs()
+
+			require.NoError(t, base.MkdirAll("/data/dir", os.ModePerm))
+			require.NoError(t, afero.WriteFile(base, "/data/a.txt", nil, 0o644))
+
+			ft := newFakeT(t)
+			fs := aferomock.SandboxFs(ft, "/data", base)
+
+			err := tc.call(fs)
+			require.ErrorIs(t, err, os.ErrPermission)
+
+			errs := ft.Errors()
+
+			require.Len(t, errs, 1)
+			assert.Contains(t, errs[0], tc.expectedMessage+"\n\tgo.nhat.io/aferomock_test.TestSandboxFs_Escape.")
+
+			// The call is not made.
+			exists, err := afero.Exists(base, "/data/a.txt")
+			require.NoError(t, err)
+			assert.True(t, exists)
+		})
+	}
+}
+
+func TestSandboxFs_NoSymlink(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+	fs := aferomock.SandboxFs(ft, "/data", aferomock.OverrideFs(afero.NewMemMapFs(), aferomock.FsCallbacks{}))
+
+	require.NoError(t, afero.WriteFile(fs, "/data/a.txt", nil, 0o644))
+
+	fi, lstatCalled, err := fs.LstatIfPossible("/data/a.txt")
+	require.NoError(t, err)
+	assert.False(t, lstatCalled)
+	assert.Equal(t, "a.txt", fi.Name())
+
+	err = fs.SymlinkIfPossible("a.txt", "/data/link")
+	require.ErrorIs(t, err, afero.ErrNoSymlink)
+
+	_, err = fs.ReadlinkIfPossible("/data/link")
+	require.ErrorIs(t, err, afero.ErrNoReadlink)
+
+	assert.Empty(t, ft.Errors())
+}
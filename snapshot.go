@@ -0,0 +1,380 @@
+package aferomock
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+var errUnsupportedFileType = errors.New("unsupported file type")
+
+// Snapshot is the state of a file system at a point in time. It can be cloned into new InodeFs, or restored into the
+// file system it was taken from.
+//
+// The snapshot of an InodeFs shares the data of the files with the InodeFs and with its clones, the data is only copied
+// when it is written, so that many subtests can fork from one fixture.
+//
+//	fixture := aferomock.NewInodeFs()
+//	// Build the fixture.
+//
+//	snap, err := aferomock.TakeSnapshot(fixture)
+//	require.NoError(t, err)
+//
+//	for _, tc := range testCases {
+//		t.Run(tc.scenario, func(t *testing.T) {
+//			fs := snap.Clone()
+//			// Run the test.
+//		})
+//	}
+type Snapshot struct {
+	fs   afero.Fs
+	tree *inodeTree
+
+	// names are the relative paths of the entries in fs, by their path from the root, see walkEntries.
+	names map[string]string
+}
+
+// Changes are the paths that differ between two file systems, sorted.
+type Changes struct {
+	// Added are the paths that only exist in the second file system.
+	Added []string
+	// Removed are the paths that only exist in the first file system.
+	Removed []string
+	// Modified are the paths whose type, permissions, content or link target differ.
+	Modified []string
+}
+
+// IsEmpty reports whether there is no change.
+func (c Changes) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// TakeSnapshot takes a snapshot of the file system. The snapshot of an InodeFs is a copy-on-write copy of its inodes,
+// the other file systems are walked from the root with afero.Walk, and their files are read. The relative paths of an
+// afero.MemMapFs, such as "data/a.txt", are taken from the root, like "/data/a.txt".
+//
+// The whole file system is walked, wrap an afero.OsFs into an afero.BasePathFs to only take a snapshot of a directory.
+func TakeSnapshot(fs afero.Fs) (*Snapshot, error) {
+	if ifs, ok := fs.(*InodeFs); ok {
+		return &Snapshot{fs: fs, tree: ifs.tree.clone()}, nil
+	}
+
+	entries, err := walkEntries(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewInodeFs()
+
+	if err := writeEntries(c, entries); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+
+	for p, e := range entries {
+		if e.name != p {
+			names[p] = e.name
+		}
+	}
+
+	return &Snapshot{fs: fs, tree: c.tree, names: names}, nil
+}
+
+// Clone returns a new InodeFs with the state of the snapshot. The clones are independent of each other, and of the
+// snapshot.
+func (s *Snapshot) Clone() *InodeFs {
+	return newInodeFs(s.tree.clone())
+}
+
+// Restore restores the state of the snapshot into the file system it was taken from. An InodeFs gets a copy-on-write
+// copy of the inodes of the snapshot, the other file systems are emptied and the files of the snapshot are written
+// into them.
+func (s *Snapshot) Restore() error {
+	if ifs, ok := s.fs.(*InodeFs); ok {
+		c := s.tree.clone()
+
+		ifs.tree.mu.Lock()
+		defer ifs.tree.mu.Unlock()
+
+		ifs.tree.root, ifs.tree.lastIno = c.root, c.lastIno
+
+		return nil
+	}
+
+	names, err := afero.ReadDir(s.fs, string(filepath.Separator))
+	if err != nil {
+		return err
+	}
+
+	for _, fi := range names {
+		if err := s.fs.RemoveAll(rootEntry(s.fs, fi.Name())); err != nil {
+			return err
+		}
+	}
+
+	entries, err := walkEntries(newInodeFs(s.tree))
+	if err != nil {
+		return err
+	}
+
+	restored := make(map[string]fsEntry, len(entries))
+
+	for p, e := range entries {
+		if name, ok := s.names[p]; ok {
+			p = name
+		}
+
+		restored[p] = e
+	}
+
+	return writeEntries(s.fs, restored)
+}
+
+// Diff walks two file systems from the root and returns the paths that differ. The modification times are not
+// compared. The relative paths of an afero.MemMapFs are compared from the root, like TakeSnapshot.
+func Diff(a, b afero.Fs) (Changes, error) {
+	before, err := walkEntries(a)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	after, err := walkEntries(b)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	return diffEntries(before, after), nil
+}
+
+func diffEntries(before, after map[string]fsEntry) Changes {
+	var c Changes
+
+	for name, e := range before {
+		other, ok := after[name]
+
+		switch {
+		case !ok:
+			c.Removed = append(c.Removed, name)
+
+		case !e.equal(other):
+			c.Modified = append(c.Modified, name)
+		}
+	}
+
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			c.Added = append(c.Added, name)
+		}
+	}
+
+	sort.Strings(c.Added)
+	sort.Strings(c.Removed)
+	sort.Strings(c.Modified)
+
+	return c
+}
+
+// clone returns a copy of the tree. The data of the files is shared with the copy until it is written.
+func (t *inodeTree) clone() *inodeTree {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	copies := make(map[*inode]*inode)
+
+	return &inodeTree{root: cloneInode(t.root, copies), lastIno: t.lastIno}
+}
+
+// cloneInode copies an inode and its entries, an inode with several links is only copied once.
+func cloneInode(n *inode, copies map[*inode]*inode) *inode {
+	if c, ok := copies[n]; ok {
+		return c
+	}
+
+	c := *n
+	c.shared, n.shared = true, true
+	copies[n] = &c
+
+	if n.entries != nil {
+		c.entries = make(map[string]*inode, len(n.entries))
+
+		for name, e := range n.entries {
+			c.entries[name] = cloneInode(e, copies)
+		}
+	}
+
+	return &c
+}
+
+// fsEntry is a file, a directory or a symbolic link found by walkEntries.
+type fsEntry struct {
+	// name is the path of the entry in the file system, it is relative for the relative paths of a MemMapFs.
+	name    string
+	mode    os.FileMode
+	modTime time.Time
+	data    []byte
+	target  string
+}
+
+func (e fsEntry) equal(other fsEntry) bool {
+	return e.mode == other.mode && e.target == other.target && bytes.Equal(e.data, other.data)
+}
+
+// walkEntries walks the file system from the root, the root itself is not returned. The entries are keyed by their
+// path from the root.
+//
+// An afero.MemMapFs lists its relative paths, such as "data" for "data/a.txt", in the root, where they do not exist.
+// They are walked from their relative path, and keyed like "/data" and "/data/a.txt".
+func walkEntries(fs afero.Fs) (map[string]fsEntry, error) {
+	root := string(filepath.Separator)
+	entries := make(map[string]fsEntry)
+
+	err := afero.Walk(fs, root, func(path string, fi os.FileInfo, err error) error {
+		if err == nil {
+			if path == root {
+				return nil
+			}
+
+			return addEntry(fs, entries, path, path, fi)
+		}
+
+		if path == root || filepath.Dir(path) != root || !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		rel := strings.TrimPrefix(path, root)
+
+		if rootEntry(fs, rel) != rel {
+			return err
+		}
+
+		return afero.Walk(fs, rel, func(name string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			return addEntry(fs, entries, filepath.Join(root, name), name, fi)
+		})
+	})
+
+	return entries, err
+}
+
+// addEntry reads the entry at the name in the file system, and adds it at the path.
+func addEntry(fs afero.Fs, entries map[string]fsEntry, path, name string, fi os.FileInfo) error {
+	e := fsEntry{name: name, mode: fi.Mode(), modTime: fi.ModTime()}
+
+	var err error
+
+	switch {
+	case fi.Mode()&os.ModeSymlink != 0:
+		r, ok := fs.(afero.LinkReader)
+		if !ok {
+			return &os.PathError{Op: "readlink", Path: name, Err: afero.ErrNoReadlink}
+		}
+
+		if e.target, err = r.ReadlinkIfPossible(name); err != nil {
+			return err
+		}
+
+	case fi.Mode().IsRegular():
+		if e.data, err = afero.ReadFile(fs, name); err != nil {
+			return err
+		}
+	}
+
+	entries[path] = e
+
+	return nil
+}
+
+// rootEntry returns the path of an entry of the root, it is the relative name when only the relative path exists, like
+// the relative paths of a MemMapFs.
+func rootEntry(fs afero.Fs, name string) string {
+	p := filepath.Join(string(filepath.Separator), name)
+
+	if _, err := lstatIfPossible(fs, p); !errors.Is(err, os.ErrNotExist) {
+		return p
+	}
+
+	if _, err := lstatIfPossible(fs, name); err == nil {
+		return name
+	}
+
+	return p
+}
+
+func lstatIfPossible(fs afero.Fs, name string) (os.FileInfo, error) {
+	if l, ok := fs.(afero.Lstater); ok {
+		fi, _, err := l.LstatIfPossible(name)
+
+		return fi, err
+	}
+
+	return fs.Stat(name)
+}
+
+// writeEntries writes the entries into the file system, the parents first.
+func writeEntries(fs afero.Fs, entries map[string]fsEntry) error {
+	names := make([]string, 0, len(entries))
+
+	for name := range entries {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := writeEntry(fs, name, entries[name]); err != nil {
+			return err
+		}
+	}
+
+	// The times of the directories are set last, because writing their entries changes them.
+	for i := len(names) - 1; i >= 0; i-- {
+		e := entries[names[i]]
+
+		if e.mode&os.ModeSymlink != 0 {
+			continue
+		}
+
+		if err := fs.Chtimes(names[i], e.modTime, e.modTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeEntry(fs afero.Fs, name string, e fsEntry) error {
+	switch {
+	case e.mode.IsDir():
+		if err := fs.Mkdir(name, e.mode.Perm()); err != nil {
+			return err
+		}
+
+	case e.mode&os.ModeSymlink != 0:
+		l, ok := fs.(afero.Linker)
+		if !ok {
+			return &os.LinkError{Op: "symlink", Old: e.target, New: name, Err: afero.ErrNoSymlink}
+		}
+
+		return l.SymlinkIfPossible(e.target, name)
+
+	case e.mode.IsRegular():
+		if err := afero.WriteFile(fs, name, e.data, e.mode.Perm()); err != nil {
+			return err
+		}
+
+	default:
+		return &os.PathError{Op: "snapshot", Path: name, Err: errUnsupportedFileType}
+	}
+
+	return fs.Chmod(name, e.mode.Perm())
+}
@@ -0,0 +1,213 @@
+package aferomock_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func newFixture(t *testing.T, fs afero.Fs) {
+	t.Helper()
+
+	require.NoError(t, fs.MkdirAll("/data/dir", os.ModePerm))
+	require.NoError(t, afero.WriteFile(fs, "/data/a.txt", []byte("hello"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/data/dir/b.txt", []byte("world"), 0o600))
+}
+
+func TestSnapshot_Clone(t *testing.T) {
+	t.Parallel()
+
+	fixture := aferomock.NewInodeFs()
+
+	newFixture(t, fixture)
+	require.NoError(t, fixture.LinkIfPossible("/data/a.txt", "/data/hardlink"))
+	require.NoError(t, fixture.SymlinkIfPossible("a.txt", "/data/symlink"))
+
+	snap, err := aferomock.TakeSnapshot(fixture)
+	require.NoError(t, err)
+
+	// The fixture is changed after the snapshot.
+	require.NoError(t, afero.WriteFile(fixture, "/data/a.txt", []byte("changed"), 0o644))
+
+	for _, scenario := range []string{"first", "second", "third"} {
+		t.Run(scenario, func(t *testing.T) {
+			t.Parallel()
+
+			fs := snap.Clone()
+
+			data, err := afero.ReadFile(fs, "/data/symlink")
+			require.NoError(t, err)
+			assert.Equal(t, "hello", string(data))
+
+			// The write is visible through the hard link of the clone only.
+			f, err := fs.OpenFile("/data/a.txt", os.O_WRONLY, 0)
+			require.NoError(t, err)
+
+			_, err = f.WriteString("HE")
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			data, err = afero.ReadFile(fs, "/data/hardlink")
+			require.NoError(t, err)
+			assert.Equal(t, "HEllo", string(data))
+
+			require.NoError(t, fs.RemoveAll("/data/dir"))
+		})
+	}
+
+	t.Cleanup(func() {
+		fs := snap.Clone()
+
+		data, err := afero.ReadFile(fs, "/data/a.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+
+		exists, err := afero.Exists(fs, "/data/dir/b.txt")
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
+
+func TestSnapshot_Restore(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		fs       afero.Fs
+	}{
+		{scenario: "InodeFs", fs: aferomock.NewInodeFs()},
+		{scenario: "MemMapFs", fs: afero.NewMemMapFs()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			newFixture(t, tc.fs)
+
+			mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			require.NoError(t, tc.fs.Chtimes("/data/a.txt", mtime, mtime))
+
+			snap, err := aferomock.TakeSnapshot(tc.fs)
+			require.NoError(t, err)
+
+			require.NoError(t, afero.WriteFile(tc.fs, "/data/a.txt", []byte("changed"), 0o644))
+			require.NoError(t, tc.fs.Remove("/data/dir/b.txt"))
+			require.NoError(t, afero.WriteFile(tc.fs, "/data/c.txt", nil, 0o644))
+
+			require.NoError(t, snap.Restore())
+
+			changes, err := aferomock.Diff(snap.Clone(), tc.fs)
+			require.NoError(t, err)
+			assert.True(t, changes.IsEmpty(), "unexpected changes: %+v", changes)
+
+			fi, err := tc.fs.Stat("/data/a.txt")
+			require.NoError(t, err)
+			assert.True(t, mtime.Equal(fi.ModTime()))
+
+			fi, err = tc.fs.Stat("/data/dir/b.txt")
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o600), fi.Mode())
+		})
+	}
+}
+
+func TestSnapshot_RelativePaths(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+
+	require.NoError(t, afero.WriteFile(fs, "data/a.txt", []byte("hello"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/abs/b.txt", []byte("world"), 0o644))
+
+	snap, err := aferomock.TakeSnapshot(fs)
+	require.NoError(t, err)
+
+	data, err := afero.ReadFile(snap.Clone(), "/data/a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	require.NoError(t, afero.WriteFile(fs, "data/a.txt", []byte("changed"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "data/c.txt", nil, 0o644))
+
+	changes, err := aferomock.Diff(snap.Clone(), fs)
+	require.NoError(t, err)
+
+	expected := aferomock.Changes{
+		Added:    []string{"/data/c.txt"},
+		Modified: []string{"/data/a.txt"},
+	}
+
+	assert.Equal(t, expected, changes)
+
+	require.NoError(t, snap.Restore())
+
+	// The relative paths are restored as relative paths.
+	data, err = afero.ReadFile(fs, "data/a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	_, err = fs.Stat("data/c.txt")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = fs.Stat("/data/a.txt")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	changes, err = aferomock.Diff(snap.Clone(), fs)
+	require.NoError(t, err)
+	assert.True(t, changes.IsEmpty(), "unexpected changes: %+v", changes)
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	a := afero.NewMemMapFs()
+	b := aferomock.NewInodeFs()
+
+	newFixture(t, a)
+	newFixture(t, b)
+
+	require.NoError(t, afero.WriteFile(b, "/data/a.txt", []byte("changed"), 0o644))
+	require.NoError(t, b.Chmod("/data/dir", 0o700))
+	require.NoError(t, b.Remove("/data/dir/b.txt"))
+	require.NoError(t, afero.WriteFile(b, "/data/c.txt", nil, 0o644))
+	require.NoError(t, b.SymlinkIfPossible("c.txt", "/data/link"))
+
+	// The modification times are not compared.
+	require.NoError(t, b.Chtimes("/data", time.Now(), time.Now()))
+
+	changes, err := aferomock.Diff(a, b)
+	require.NoError(t, err)
+
+	expected := aferomock.Changes{
+		Added:    []string{"/data/c.txt", "/data/link"},
+		Removed:  []string{"/data/dir/b.txt"},
+		Modified: []string{"/data/a.txt", "/data/dir"},
+	}
+
+	assert.Equal(t, expected, changes)
+	assert.False(t, changes.IsEmpty())
+}
+
+func TestDiff_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.OverrideFs(afero.NewMemMapFs(), aferomock.FsCallbacks{
+		StatFunc: func(name string) (os.FileInfo, error) {
+			return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrPermission}
+		},
+	})
+
+	_, err := aferomock.Diff(afero.NewMemMapFs(), fs)
+	require.ErrorIs(t, err, os.ErrPermission)
+
+	_, err = aferomock.TakeSnapshot(fs)
+	require.ErrorIs(t, err, os.ErrPermission)
+}
@@ -0,0 +1,197 @@
+package aferomock
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/mock"
+)
+
+const filePrefix = "File."
+
+// Spy wraps an afero.Fs and records the calls of its methods, and of the methods of the files that it opens or
+// creates.
+//
+// The methods of afero.Fs and afero.Symlinker are named as is, for example Stat, and the methods of afero.File are
+// prefixed with File., for example File.Stat.
+//
+//	fs := aferomock.NewSpy(afero.NewMemMapFs())
+//
+//	// ...
+//
+//	assert.Equal(t, 2, fs.CallCount("Stat", "config.yaml"))
+//	fs.AssertNotCalled(t, "File.Write", mock.Anything)
+type Spy struct {
+	SymlinkerCallbacks
+
+	mu    sync.Mutex
+	calls []Op
+}
+
+// NewSpy creates a new Spy.
+func NewSpy(fs afero.Fs) *Spy {
+	s := &Spy{}
+
+	s.SymlinkerCallbacks = Intercept(fs, Hooks{
+		After: func(op *Op) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			s.calls = append(s.calls, recordedOp(op))
+		},
+	})
+
+	return s
+}
+
+// recordedOp copies the call, the buffers of Read and Write are copied because the caller reuses them.
+func recordedOp(op *Op) Op {
+	c := *op
+	c.Args = make([]interface{}, len(op.Args))
+
+	for i, arg := range op.Args {
+		if b, ok := arg.([]byte); ok {
+			arg = append([]byte(nil), b...)
+		}
+
+		c.Args[i] = arg
+	}
+
+	return c
+}
+
+// Calls returns the recorded calls of the method, in order. All the calls are returned if the method is empty.
+func (s *Spy) Calls(method string) []Op {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	calls := make([]Op, 0, len(s.calls))
+
+	for _, c := range s.calls {
+		if method == "" || spyMethod(c) == method {
+			calls = append(calls, c)
+		}
+	}
+
+	return calls
+}
+
+// CallCount returns the number of calls of the method on the path. The path is the first argument of the methods of
+// afero.Fs, and the name of the file for the methods of afero.File. It is matched like the arguments of testify, so
+// mock.Anything and mock.MatchedBy can be used.
+func (s *Spy) CallCount(method string, path interface{}) int {
+	count := 0
+
+	for _, c := range s.Calls(method) {
+		if _, diff := (mock.Arguments{path}).Diff([]interface{}{spyPath(c)}); diff == 0 {
+			count++
+		}
+	}
+
+	return count
+}
+
+// LastCall returns the last recorded call of the method.
+func (s *Spy) LastCall(method string) (Op, bool) {
+	calls := s.Calls(method)
+
+	if len(calls) == 0 {
+		return Op{}, false
+	}
+
+	return calls[len(calls)-1], true
+}
+
+// AssertCalled asserts that the method is called with the arguments. The arguments are matched like the arguments of
+// testify, so mock.Anything and mock.MatchedBy can be used.
+func (s *Spy) AssertCalled(t mock.TestingT, method string, args ...interface{}) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	if s.called(method, args...) {
+		return true
+	}
+
+	t.Errorf("Should have called with given arguments\nExpected %q to have been called with:\n%v\nbut actual calls were:\n%s",
+		method, args, s.callsString(method))
+
+	return false
+}
+
+// AssertNotCalled asserts that the method is not called with the arguments. The arguments are matched like the
+// arguments of testify, so mock.Anything and mock.MatchedBy can be used.
+func (s *Spy) AssertNotCalled(t mock.TestingT, method string, args ...interface{}) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	if !s.called(method, args...) {
+		return true
+	}
+
+	t.Errorf("Should not have called with given arguments\nExpected %q to not have been called with:\n%v\nbut actual calls were:\n%s",
+		method, args, s.callsString(method))
+
+	return false
+}
+
+// Reset removes the recorded calls.
+func (s *Spy) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = nil
+}
+
+func (s *Spy) called(method string, args ...interface{}) bool {
+	for _, c := range s.Calls(method) {
+		if _, diff := mock.Arguments(args).Diff(c.Args); diff == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Spy) callsString(method string) string {
+	var sb strings.Builder
+
+	for _, c := range s.Calls(method) {
+		if c.File != "" {
+			_, _ = fmt.Fprintf(&sb, "\t%s on file %q\n", callString(method, c.Args...), c.File)
+		} else {
+			_, _ = fmt.Fprintf(&sb, "\t%s\n", callString(method, c.Args...))
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "\t(none)\n"
+	}
+
+	return sb.String()
+}
+
+func spyMethod(op Op) string {
+	if op.File != "" {
+		return filePrefix + op.Method
+	}
+
+	return op.Method
+}
+
+func spyPath(op Op) string {
+	if op.File != "" {
+		return op.File
+	}
+
+	if len(op.Args) > 0 {
+		if path, ok := op.Args[0].(string); ok {
+			return path
+		}
+	}
+
+	return ""
+}
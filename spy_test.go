@@ -0,0 +1,177 @@
+package aferomock_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func newSpy(t *testing.T) *aferomock.Spy {
+	t.Helper()
+
+	fs := aferomock.NewSpy(afero.NewMemMapFs())
+
+	require.NoError(t, afero.WriteFile(fs, "config.yaml", []byte("key: value"), 0o644))
+
+	_, _ = fs.Stat("config.yaml") //nolint: errcheck
+	_, _ = fs.Stat("config.yaml") //nolint: errcheck
+	_, _ = fs.Stat("unknown")     //nolint: errcheck
+
+	return fs
+}
+
+func TestSpy_Calls(t *testing.T) {
+	t.Parallel()
+
+	fs := newSpy(t)
+
+	calls := fs.Calls("Stat")
+
+	require.Len(t, calls, 3)
+	assert.Equal(t, []interface{}{"config.yaml"}, calls[0].Args)
+	require.NoError(t, calls[0].Err())
+	require.ErrorIs(t, calls[2].Err(), os.ErrNotExist)
+
+	assert.Len(t, fs.Calls("File.Write"), 1)
+	assert.Len(t, fs.Calls(""), 6)
+	assert.Empty(t, fs.Calls("Remove"))
+}
+
+func TestSpy_Calls_CopiesBuffers(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.NewSpy(afero.NewMemMapFs())
+
+	f, err := fs.Create("data.txt")
+	require.NoError(t, err)
+
+	buf := []byte("first")
+
+	_, err = f.Write(buf)
+	require.NoError(t, err)
+
+	// The caller reuses its buffer.
+	copy(buf, "reuse")
+
+	_, err = f.Write(buf)
+	require.NoError(t, err)
+
+	calls := fs.Calls("File.Write")
+
+	require.Len(t, calls, 2)
+	assert.Equal(t, []interface{}{[]byte("first")}, calls[0].Args)
+	assert.Equal(t, []interface{}{[]byte("reuse")}, calls[1].Args)
+}
+
+func TestSpy_CallCount(t *testing.T) {
+	t.Parallel()
+
+	fs := newSpy(t)
+
+	testCases := []struct {
+		scenario string
+		method   string
+		path     interface{}
+		expected int
+	}{
+		{
+			scenario: "exact path",
+			method:   "Stat",
+			path:     "config.yaml",
+			expected: 2,
+		},
+		{
+			scenario: "any path",
+			method:   "Stat",
+			path:     mock.Anything,
+			expected: 3,
+		},
+		{
+			scenario: "matched path",
+			method:   "Stat",
+			path: mock.MatchedBy(func(path string) bool {
+				return strings.HasSuffix(path, ".yaml")
+			}),
+			expected: 2,
+		},
+		{
+			scenario: "file method",
+			method:   "File.Close",
+			path:     "config.yaml",
+			expected: 1,
+		},
+		{
+			scenario: "not called",
+			method:   "Remove",
+			path:     mock.Anything,
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, fs.CallCount(tc.method, tc.path))
+		})
+	}
+}
+
+func TestSpy_LastCall(t *testing.T) {
+	t.Parallel()
+
+	fs := newSpy(t)
+
+	c, ok := fs.LastCall("Stat")
+
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{"unknown"}, c.Args)
+
+	c, ok = fs.LastCall("File.Write")
+
+	require.True(t, ok)
+	assert.Equal(t, "config.yaml", c.File)
+	assert.Equal(t, []interface{}{10, nil}, c.Results)
+
+	_, ok = fs.LastCall("Remove")
+
+	assert.False(t, ok)
+}
+
+func TestSpy_AssertCalled(t *testing.T) {
+	t.Parallel()
+
+	fs := newSpy(t)
+
+	assert.True(t, fs.AssertCalled(t, "Stat", "config.yaml"))
+	assert.True(t, fs.AssertCalled(t, "File.Write", mock.Anything))
+	assert.True(t, fs.AssertNotCalled(t, "Remove", mock.Anything))
+
+	ft := newFakeT(t)
+
+	assert.False(t, fs.AssertCalled(ft, "Stat", "other.yaml"))
+	assert.False(t, fs.AssertNotCalled(ft, "Stat", "unknown"))
+
+	require.Len(t, ft.Errors(), 2)
+	assert.Contains(t, ft.Errors()[0], "Expected \"Stat\" to have been called with:\n[other.yaml]")
+	assert.Contains(t, ft.Errors()[0], "\tStat(\"config.yaml\")\n\tStat(\"config.yaml\")\n\tStat(\"unknown\")\n")
+	assert.Contains(t, ft.Errors()[1], "Expected \"Stat\" to not have been called with:\n[unknown]")
+}
+
+func TestSpy_Reset(t *testing.T) {
+	t.Parallel()
+
+	fs := newSpy(t)
+
+	fs.Reset()
+
+	assert.Empty(t, fs.Calls(""))
+	assert.True(t, fs.AssertNotCalled(t, "Stat", mock.Anything))
+}
@@ -0,0 +1,54 @@
+package aferomock
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const pkgPrefix = "go.nhat.io/aferomock."
+
+// callerStack returns the stack trace of the caller, excluding the frames of this package and of the runtime and
+// testing packages.
+func callerStack() string {
+	pc := make([]uintptr, 64)
+	n := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+
+	var sb strings.Builder
+
+	for {
+		frame, more := frames.Next()
+
+		if strings.HasPrefix(frame.Function, "testing.") || strings.HasPrefix(frame.Function, "runtime.") {
+			break
+		}
+
+		if !strings.HasPrefix(frame.Function, pkgPrefix) {
+			_, _ = fmt.Fprintf(&sb, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return sb.String()
+}
+
+// goroutineID returns the id of the current goroutine.
+func goroutineID() uint64 {
+	var buf [64]byte
+
+	n := runtime.Stack(buf[:], false)
+	s := strings.TrimPrefix(string(buf[:n]), "goroutine ")
+
+	if i := strings.IndexByte(s, ' '); i > 0 {
+		s = s[:i]
+	}
+
+	id, _ := strconv.ParseUint(s, 10, 64) //nolint: errcheck
+
+	return id
+}
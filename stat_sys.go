@@ -0,0 +1,390 @@
+package aferomock
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// statBlockSize is the size of the blocks counted by the Blocks of a syscall.Stat_t.
+const statBlockSize = 512
+
+// StatSys builds the payload of FileInfo.Sys, a *syscall.Stat_t on Linux and macOS.
+//
+//	fi := aferomock.FileInfoCallbacks{
+//		SysFunc: aferomock.NewStatSys().WithIno(42).WithNlink(2).SysFunc(),
+//	}
+type StatSys struct {
+	dev     uint64
+	ino     uint64
+	nlink   uint64
+	mode    os.FileMode
+	uid     uint32
+	gid     uint32
+	rdev    uint64
+	size    int64
+	blocks  int64
+	blksize int64
+	atime   time.Time
+	mtime   time.Time
+	ctime   time.Time
+}
+
+// NewStatSys creates a new StatSys of a regular file with one link.
+func NewStatSys() StatSys {
+	return StatSys{nlink: 1, blksize: 4096}
+}
+
+// WithDev sets the ID of the device that contains the file.
+func (s StatSys) WithDev(dev uint64) StatSys {
+	s.dev = dev
+
+	return s
+}
+
+// WithIno sets the inode number.
+func (s StatSys) WithIno(ino uint64) StatSys {
+	s.ino = ino
+
+	return s
+}
+
+// WithNlink sets the number of hard links.
+func (s StatSys) WithNlink(nlink uint64) StatSys {
+	s.nlink = nlink
+
+	return s
+}
+
+// WithMode sets the type and the permissions of the file, they are converted to the Unix mode bits.
+func (s StatSys) WithMode(mode os.FileMode) StatSys {
+	s.mode = mode
+
+	return s
+}
+
+// WithUID sets the user ID of the owner.
+func (s StatSys) WithUID(uid uint32) StatSys {
+	s.uid = uid
+
+	return s
+}
+
+// WithGID sets the group ID of the owner.
+func (s StatSys) WithGID(gid uint32) StatSys {
+	s.gid = gid
+
+	return s
+}
+
+// WithRdev sets the device ID of a special file.
+func (s StatSys) WithRdev(rdev uint64) StatSys {
+	s.rdev = rdev
+
+	return s
+}
+
+// WithSize sets the size, and the number of 512-byte blocks that are allocated for it.
+func (s StatSys) WithSize(size int64) StatSys {
+	s.size = size
+	s.blocks = (size + statBlockSize - 1) / statBlockSize
+
+	return s
+}
+
+// WithBlocks sets the number of 512-byte blocks that are allocated.
+func (s StatSys) WithBlocks(blocks int64) StatSys {
+	s.blocks = blocks
+
+	return s
+}
+
+// WithBlksize sets the preferred block size for I/O.
+func (s StatSys) WithBlksize(blksize int64) StatSys {
+	s.blksize = blksize
+
+	return s
+}
+
+// WithAtime sets the time of the last access.
+func (s StatSys) WithAtime(atime time.Time) StatSys {
+	s.atime = atime
+
+	return s
+}
+
+// WithMtime sets the time of the last modification.
+func (s StatSys) WithMtime(mtime time.Time) StatSys {
+	s.mtime = mtime
+
+	return s
+}
+
+// WithCtime sets the time of the last status change.
+func (s StatSys) WithCtime(ctime time.Time) StatSys {
+	s.ctime = ctime
+
+	return s
+}
+
+// WithFileInfo sets the mode, the size and the times from a fs.FileInfo. The access and the status change times are
+// set to the modification time.
+func (s StatSys) WithFileInfo(fi os.FileInfo) StatSys {
+	return s.WithMode(fi.Mode()).
+		WithSize(fi.Size()).
+		WithAtime(fi.ModTime()).
+		WithMtime(fi.ModTime()).
+		WithCtime(fi.ModTime())
+}
+
+// Ino returns the inode number.
+func (s StatSys) Ino() uint64 {
+	return s.ino
+}
+
+// Nlink returns the number of hard links.
+func (s StatSys) Nlink() uint64 {
+	return s.nlink
+}
+
+// SysFunc returns a FileInfoCallbacks.SysFunc that returns the payload.
+func (s StatSys) SysFunc() func() interface{} {
+	return s.Sys
+}
+
+// Expect sets the expectation of Sys on the FileInfo mock.
+//
+//	fi := aferomock.MockFileInfo(aferomock.NewStatSys().WithIno(42).Expect)(t)
+func (s StatSys) Expect(fi *FileInfo) {
+	fi.On("Sys").Return(s.Sys())
+}
+
+// unixMode converts the mode to the Unix mode bits.
+func (s StatSys) unixMode() uint32 {
+	const (
+		typeDir     = 0o040000
+		typeChar    = 0o020000
+		typeBlock   = 0o060000
+		typeRegular = 0o100000
+		typeFifo    = 0o010000
+		typeSymlink = 0o120000
+		typeSocket  = 0o140000
+		setuid      = 0o4000
+		setgid      = 0o2000
+		sticky      = 0o1000
+	)
+
+	m := uint32(s.mode.Perm())
+
+	switch {
+	case s.mode&os.ModeDir != 0:
+		m |= typeDir
+
+	case s.mode&os.ModeSymlink != 0:
+		m |= typeSymlink
+
+	case s.mode&os.ModeNamedPipe != 0:
+		m |= typeFifo
+
+	case s.mode&os.ModeSocket != 0:
+		m |= typeSocket
+
+	case s.mode&os.ModeCharDevice != 0:
+		m |= typeChar
+
+	case s.mode&os.ModeDevice != 0:
+		m |= typeBlock
+
+	default:
+		m |= typeRegular
+	}
+
+	if s.mode&os.ModeSetuid != 0 {
+		m |= setuid
+	}
+
+	if s.mode&os.ModeSetgid != 0 {
+		m |= setgid
+	}
+
+	if s.mode&os.ModeSticky != 0 {
+		m |= sticky
+	}
+
+	return m
+}
+
+// StatSysFs wraps an afero.Fs and sets the Sys of the FileInfo returned by Stat, File.Stat and File.Readdir to a
+// StatSys built from the template. Each path gets a stable inode number that follows the file when it is renamed and is
+// released when it is removed. The mode, the size and the times are taken from the FileInfo, and directories have two
+// links.
+func StatSysFs(fs afero.Fs, template StatSys) FsCallbacks {
+	s := &statInodes{
+		template: template,
+		inodes:   make(map[string]uint64),
+	}
+
+	open := func(name string, f afero.File, err error) (afero.File, error) {
+		if err != nil {
+			return f, err
+		}
+
+		return s.file(name, f), nil
+	}
+
+	return OverrideFs(fs, FsCallbacks{
+		CreateFunc: func(name string) (afero.File, error) {
+			f, err := fs.Create(name)
+
+			return open(name, f, err)
+		},
+		OpenFunc: func(name string) (afero.File, error) {
+			f, err := fs.Open(name)
+
+			return open(name, f, err)
+		},
+		OpenFileFunc: func(name string, flag int, perm os.FileMode) (afero.File, error) {
+			f, err := fs.OpenFile(name, flag, perm)
+
+			return open(name, f, err)
+		},
+		RemoveFunc: func(name string) error {
+			if err := fs.Remove(name); err != nil {
+				return err
+			}
+
+			s.remove(name)
+
+			return nil
+		},
+		RemoveAllFunc: func(path string) error {
+			if err := fs.RemoveAll(path); err != nil {
+				return err
+			}
+
+			s.remove(path)
+
+			return nil
+		},
+		RenameFunc: func(oldname, newname string) error {
+			if err := fs.Rename(oldname, newname); err != nil {
+				return err
+			}
+
+			s.rename(oldname, newname)
+
+			return nil
+		},
+		StatFunc: func(name string) (os.FileInfo, error) {
+			fi, err := fs.Stat(name)
+			if err != nil {
+				return nil, err
+			}
+
+			return s.fileInfo(name, fi), nil
+		},
+	})
+}
+
+type statInodes struct {
+	template StatSys
+
+	mu     sync.Mutex
+	next   uint64
+	inodes map[string]uint64
+}
+
+func (s *statInodes) inode(name string) uint64 {
+	name = filepath.Clean(name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ino, ok := s.inodes[name]
+	if !ok {
+		s.next++
+		ino = s.next
+		s.inodes[name] = ino
+	}
+
+	return ino
+}
+
+func (s *statInodes) remove(path string) {
+	path = filepath.Clean(path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name := range s.inodes {
+		if isSubPath(path, name) {
+			delete(s.inodes, name)
+		}
+	}
+}
+
+func (s *statInodes) rename(oldname, newname string) {
+	oldname = filepath.Clean(oldname)
+	newname = filepath.Clean(newname)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	moved := make(map[string]uint64)
+
+	for name, ino := range s.inodes {
+		if isSubPath(oldname, name) {
+			moved[newname+strings.TrimPrefix(name, oldname)] = ino
+
+			delete(s.inodes, name)
+		}
+	}
+
+	for name := range s.inodes {
+		if isSubPath(newname, name) {
+			delete(s.inodes, name)
+		}
+	}
+
+	for name, ino := range moved {
+		s.inodes[name] = ino
+	}
+}
+
+func (s *statInodes) fileInfo(name string, fi os.FileInfo) os.FileInfo {
+	sys := s.template.WithFileInfo(fi).WithIno(s.inode(name))
+
+	if fi.IsDir() {
+		sys = sys.WithNlink(2)
+	}
+
+	return OverrideFileInfo(fi, FileInfoCallbacks{
+		SysFunc: sys.SysFunc(),
+	})
+}
+
+func (s *statInodes) file(name string, f afero.File) FileCallbacks {
+	return OverrideFile(f, FileCallbacks{
+		ReaddirFunc: func(count int) ([]os.FileInfo, error) {
+			fis, err := f.Readdir(count)
+
+			for i, fi := range fis {
+				fis[i] = s.fileInfo(filepath.Join(name, fi.Name()), fi)
+			}
+
+			return fis, err
+		},
+		StatFunc: func() (os.FileInfo, error) {
+			fi, err := f.Stat()
+			if err != nil {
+				return nil, err
+			}
+
+			return s.fileInfo(name, fi), nil
+		},
+	})
+}
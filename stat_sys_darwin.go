@@ -0,0 +1,9 @@
+package aferomock
+
+import "syscall"
+
+func setTimes(st *syscall.Stat_t, atime, mtime, ctime syscall.Timespec) {
+	st.Atimespec = atime
+	st.Mtimespec = mtime
+	st.Ctimespec = ctime
+}
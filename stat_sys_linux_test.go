@@ -0,0 +1,26 @@
+package aferomock_test
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestStatSys_Times(t *testing.T) {
+	t.Parallel()
+
+	atime := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	mtime := atime.Add(time.Hour)
+	ctime := mtime.Add(time.Hour)
+
+	st := aferomock.NewStatSys().WithAtime(atime).WithMtime(mtime).WithCtime(ctime).Stat()
+
+	assert.Equal(t, syscall.NsecToTimespec(atime.UnixNano()), st.Atim)
+	assert.Equal(t, syscall.NsecToTimespec(mtime.UnixNano()), st.Mtim)
+	assert.Equal(t, syscall.NsecToTimespec(ctime.UnixNano()), st.Ctim)
+	assert.Equal(t, syscall.Timespec{}, aferomock.NewStatSys().Stat().Mtim)
+}
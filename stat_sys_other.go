@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package aferomock
+
+// Sys returns the payload, it is nil because syscall.Stat_t is only supported on Linux and macOS.
+func (s StatSys) Sys() interface{} {
+	return nil
+}
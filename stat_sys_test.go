@@ -0,0 +1,156 @@
+//go:build linux || darwin
+
+package aferomock_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func statT(t *testing.T, fi os.FileInfo) *syscall.Stat_t {
+	t.Helper()
+
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	require.True(t, ok, "Sys() is not a *syscall.Stat_t: %T", fi.Sys())
+
+	return st
+}
+
+func TestStatSys(t *testing.T) {
+	t.Parallel()
+
+	sys := aferomock.NewStatSys().
+		WithDev(1).
+		WithIno(42).
+		WithNlink(3).
+		WithMode(os.ModeDir | 0o750).
+		WithUID(1000).
+		WithGID(100).
+		WithSize(1000)
+
+	fi := aferomock.FileInfoCallbacks{SysFunc: sys.SysFunc()}
+
+	st := statT(t, fi)
+
+	assert.EqualValues(t, 1, st.Dev)
+	assert.EqualValues(t, 42, st.Ino)
+	assert.EqualValues(t, 3, st.Nlink)
+	assert.EqualValues(t, syscall.S_IFDIR|0o750, st.Mode)
+	assert.EqualValues(t, 1000, st.Uid)
+	assert.EqualValues(t, 100, st.Gid)
+	assert.EqualValues(t, 1000, st.Size)
+	assert.EqualValues(t, 2, st.Blocks)
+	assert.EqualValues(t, 4096, st.Blksize)
+}
+
+func TestStatSys_Mode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		mode     os.FileMode
+		expected uint32
+	}{
+		{scenario: "regular", mode: 0o644, expected: syscall.S_IFREG | 0o644},
+		{scenario: "dir", mode: os.ModeDir | 0o755, expected: syscall.S_IFDIR | 0o755},
+		{scenario: "symlink", mode: os.ModeSymlink | 0o777, expected: syscall.S_IFLNK | 0o777},
+		{scenario: "fifo", mode: os.ModeNamedPipe | 0o600, expected: syscall.S_IFIFO | 0o600},
+		{scenario: "socket", mode: os.ModeSocket | 0o600, expected: syscall.S_IFSOCK | 0o600},
+		{scenario: "char device", mode: os.ModeDevice | os.ModeCharDevice | 0o600, expected: syscall.S_IFCHR | 0o600},
+		{scenario: "block device", mode: os.ModeDevice | 0o600, expected: syscall.S_IFBLK | 0o600},
+		{scenario: "setuid", mode: os.ModeSetuid | os.ModeSticky | 0o755, expected: syscall.S_IFREG | syscall.S_ISUID | syscall.S_ISVTX | 0o755},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			st := aferomock.NewStatSys().WithMode(tc.mode).Stat()
+
+			assert.EqualValues(t, tc.expected, st.Mode)
+		})
+	}
+}
+
+func TestStatSys_Expect(t *testing.T) {
+	t.Parallel()
+
+	fi := aferomock.MockFileInfo(aferomock.NewStatSys().WithIno(42).Expect)(t)
+
+	assert.EqualValues(t, 42, statT(t, fi).Ino)
+}
+
+func TestStatSysFs(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.StatSysFs(afero.NewMemMapFs(), aferomock.NewStatSys().WithUID(1000).WithGID(100))
+
+	require.NoError(t, fs.MkdirAll("data", os.ModePerm))
+	require.NoError(t, afero.WriteFile(fs, "data/a.txt", []byte("hello"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "data/b.txt", []byte("world"), 0o644))
+
+	fi, err := fs.Stat("data/a.txt")
+	require.NoError(t, err)
+
+	a := statT(t, fi)
+
+	assert.EqualValues(t, 1000, a.Uid)
+	assert.EqualValues(t, 100, a.Gid)
+	assert.EqualValues(t, 5, a.Size)
+	assert.EqualValues(t, 1, a.Nlink)
+
+	fi, err = fs.Stat("data")
+	require.NoError(t, err)
+
+	dir := statT(t, fi)
+
+	assert.EqualValues(t, 2, dir.Nlink)
+	assert.NotEqual(t, a.Ino, dir.Ino)
+
+	// The inode is stable across Stat, File.Stat and Readdir.
+	f, err := fs.Open("data/a.txt")
+	require.NoError(t, err)
+
+	fi, err = f.Stat()
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, a.Ino, statT(t, fi).Ino)
+
+	d, err := fs.Open("data")
+	require.NoError(t, err)
+
+	fis, err := d.Readdir(-1)
+	require.NoError(t, err)
+	require.NoError(t, d.Close())
+
+	for _, fi := range fis {
+		if fi.Name() == "a.txt" {
+			assert.Equal(t, a.Ino, statT(t, fi).Ino)
+		}
+	}
+
+	// The inode follows the file when it is renamed.
+	require.NoError(t, fs.Rename("data/a.txt", "data/c.txt"))
+
+	fi, err = fs.Stat("data/c.txt")
+	require.NoError(t, err)
+
+	assert.Equal(t, a.Ino, statT(t, fi).Ino)
+
+	// A new file at a removed path gets a new inode.
+	require.NoError(t, fs.Remove("data/c.txt"))
+	require.NoError(t, afero.WriteFile(fs, "data/c.txt", []byte("again"), 0o644))
+
+	fi, err = fs.Stat("data/c.txt")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, a.Ino, statT(t, fi).Ino)
+}
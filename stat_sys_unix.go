@@ -0,0 +1,46 @@
+//go:build linux || darwin
+
+package aferomock
+
+import (
+	"syscall"
+	"time"
+)
+
+// Sys returns the payload, a *syscall.Stat_t.
+func (s StatSys) Sys() interface{} {
+	return s.Stat()
+}
+
+// Stat returns the payload as a *syscall.Stat_t.
+func (s StatSys) Stat() *syscall.Stat_t {
+	st := &syscall.Stat_t{}
+
+	setInt(&st.Dev, s.dev)
+	setInt(&st.Ino, s.ino)
+	setInt(&st.Nlink, s.nlink)
+	setInt(&st.Mode, uint64(s.unixMode()))
+	setInt(&st.Uid, uint64(s.uid))
+	setInt(&st.Gid, uint64(s.gid))
+	setInt(&st.Rdev, s.rdev)
+	setInt(&st.Size, uint64(s.size))       //nolint: gosec
+	setInt(&st.Blocks, uint64(s.blocks))   //nolint: gosec
+	setInt(&st.Blksize, uint64(s.blksize)) //nolint: gosec
+
+	setTimes(st, timespec(s.atime), timespec(s.mtime), timespec(s.ctime))
+
+	return st
+}
+
+// setInt sets an integer field of a syscall.Stat_t, whose types depend on the OS and the architecture.
+func setInt[T ~int16 | ~int32 | ~int64 | ~uint16 | ~uint32 | ~uint64](dst *T, v uint64) {
+	*dst = T(v)
+}
+
+func timespec(t time.Time) syscall.Timespec {
+	if t.IsZero() {
+		return syscall.Timespec{}
+	}
+
+	return syscall.NsecToTimespec(t.UnixNano())
+}
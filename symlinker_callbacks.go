@@ -0,0 +1,101 @@
+package aferomock
+
+import (
+	"io/fs"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	_ afero.Fs        = SymlinkerCallbacks{}
+	_ afero.Symlinker = SymlinkerCallbacks{}
+)
+
+// SymlinkerFs is an afero.Fs that supports symbolic links.
+type SymlinkerFs interface {
+	afero.Fs
+	afero.Symlinker
+}
+
+// SymlinkerCallbacks is a callback-based mock for an afero.Fs that implements afero.Symlinker.
+type SymlinkerCallbacks struct {
+	FsCallbacks
+
+	LstatIfPossibleFunc    func(name string) (fs.FileInfo, bool, error)
+	SymlinkIfPossibleFunc  func(oldname, newname string) error
+	ReadlinkIfPossibleFunc func(name string) (string, error)
+}
+
+func (fs SymlinkerCallbacks) unset(method string, args ...interface{}) error {
+	return unset(fs.Unset, "SymlinkerCallbacks", method, args...)
+}
+
+// LstatIfPossible satisfies the afero.Lstater interface.
+func (fs SymlinkerCallbacks) LstatIfPossible(name string) (fs.FileInfo, bool, error) {
+	switch {
+	case fs.LstatIfPossibleFunc != nil:
+		return fs.LstatIfPossibleFunc(name)
+
+	case fs.Fallback != nil:
+		if l, ok := fs.Fallback.(afero.Lstater); ok {
+			return l.LstatIfPossible(name)
+		}
+	}
+
+	return nil, false, fs.unset("LstatIfPossible", name)
+}
+
+// SymlinkIfPossible satisfies the afero.Linker interface.
+func (fs SymlinkerCallbacks) SymlinkIfPossible(oldname, newname string) error {
+	switch {
+	case fs.SymlinkIfPossibleFunc != nil:
+		return fs.SymlinkIfPossibleFunc(oldname, newname)
+
+	case fs.Fallback != nil:
+		if l, ok := fs.Fallback.(afero.Linker); ok {
+			return l.SymlinkIfPossible(oldname, newname)
+		}
+	}
+
+	return fs.unset("SymlinkIfPossible", oldname, newname)
+}
+
+// ReadlinkIfPossible satisfies the afero.LinkReader interface.
+func (fs SymlinkerCallbacks) ReadlinkIfPossible(name string) (string, error) {
+	switch {
+	case fs.ReadlinkIfPossibleFunc != nil:
+		return fs.ReadlinkIfPossibleFunc(name)
+
+	case fs.Fallback != nil:
+		if l, ok := fs.Fallback.(afero.LinkReader); ok {
+			return l.ReadlinkIfPossible(name)
+		}
+	}
+
+	return "", fs.unset("ReadlinkIfPossible", name)
+}
+
+// OverrideSymlinker overrides an afero.Fs that supports symbolic links with custom callbacks, like OverrideFs.
+//
+//	fs := aferomock.OverrideSymlinker(aferomock.NewInodeFs(), aferomock.SymlinkerCallbacks{
+//		ReadlinkIfPossibleFunc: func(name string) (string, error) {
+//			return "", errors.New("readlink error")
+//		},
+//	})
+func OverrideSymlinker(fs SymlinkerFs, c SymlinkerCallbacks) SymlinkerCallbacks {
+	c.FsCallbacks = OverrideFs(fs, c.FsCallbacks)
+
+	if c.LstatIfPossibleFunc == nil {
+		c.LstatIfPossibleFunc = fs.LstatIfPossible
+	}
+
+	if c.SymlinkIfPossibleFunc == nil {
+		c.SymlinkIfPossibleFunc = fs.SymlinkIfPossible
+	}
+
+	if c.ReadlinkIfPossibleFunc == nil {
+		c.ReadlinkIfPossibleFunc = fs.ReadlinkIfPossible
+	}
+
+	return c
+}
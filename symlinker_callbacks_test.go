@@ -0,0 +1,143 @@
+package aferomock_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestSymlinkerCallbacks_Callbacks(t *testing.T) {
+	t.Parallel()
+
+	fi := aferomock.WalkFile("link", 0).FileInfo()
+
+	fs := aferomock.SymlinkerCallbacks{
+		LstatIfPossibleFunc: func(name string) (os.FileInfo, bool, error) {
+			assert.Equal(t, "link", name)
+
+			return fi, true, nil
+		},
+		SymlinkIfPossibleFunc: func(oldname, newname string) error {
+			assert.Equal(t, "target", oldname)
+			assert.Equal(t, "link", newname)
+
+			return errors.New("symlink error")
+		},
+		ReadlinkIfPossibleFunc: func(name string) (string, error) {
+			assert.Equal(t, "link", name)
+
+			return "target", nil
+		},
+	}
+
+	actual, lstatCalled, err := fs.LstatIfPossible("link")
+	require.NoError(t, err)
+	assert.True(t, lstatCalled)
+	assert.Equal(t, fi.Name(), actual.Name())
+
+	require.EqualError(t, fs.SymlinkIfPossible("target", "link"), "symlink error")
+
+	target, err := fs.ReadlinkIfPossible("link")
+	require.NoError(t, err)
+	assert.Equal(t, "target", target)
+}
+
+func TestSymlinkerCallbacks_Fallback(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.SymlinkerCallbacks{
+		FsCallbacks: aferomock.FsCallbacks{Fallback: afero.NewOsFs()},
+	}
+
+	link := filepath.Join(t.TempDir(), "link")
+
+	require.NoError(t, fs.SymlinkIfPossible("target", link))
+
+	target, err := fs.ReadlinkIfPossible(link)
+	require.NoError(t, err)
+	assert.Equal(t, "target", target)
+
+	fi, lstatCalled, err := fs.LstatIfPossible(link)
+	require.NoError(t, err)
+	assert.True(t, lstatCalled)
+	assert.NotZero(t, fi.Mode()&os.ModeSymlink)
+}
+
+func TestSymlinkerCallbacks_Unset(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		fs       aferomock.SymlinkerCallbacks
+	}{
+		{
+			scenario: "no fallback",
+		},
+		{
+			scenario: "fallback without symlinks",
+			fs: aferomock.SymlinkerCallbacks{
+				FsCallbacks: aferomock.FsCallbacks{Fallback: aferomock.FsCallbacks{}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			tc.fs.Unset = aferomock.ReturnUnset(aferomock.ErrNotImplemented)
+
+			_, _, err := tc.fs.LstatIfPossible("link")
+			require.ErrorIs(t, err, aferomock.ErrNotImplemented)
+
+			err = tc.fs.SymlinkIfPossible("target", "link")
+			require.ErrorIs(t, err, aferomock.ErrNotImplemented)
+
+			_, err = tc.fs.ReadlinkIfPossible("link")
+			require.ErrorIs(t, err, aferomock.ErrNotImplemented)
+		})
+	}
+}
+
+func TestSymlinkerCallbacks_UnsetPanic(t *testing.T) {
+	t.Parallel()
+
+	assert.PanicsWithValue(t,
+		`aferomock: SymlinkerCallbacks.ReadlinkIfPossible("link") is called but SymlinkerCallbacks.ReadlinkIfPossibleFunc is not set`,
+		func() {
+			_, _ = aferomock.SymlinkerCallbacks{}.ReadlinkIfPossible("link") //nolint: errcheck
+		},
+	)
+}
+
+func TestOverrideSymlinker(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.OverrideSymlinker(aferomock.NewInodeFs(), aferomock.SymlinkerCallbacks{
+		ReadlinkIfPossibleFunc: func(string) (string, error) {
+			return "", errors.New("readlink error")
+		},
+	})
+
+	require.NoError(t, afero.WriteFile(fs, "a.txt", []byte("hello"), 0o644))
+	require.NoError(t, fs.SymlinkIfPossible("a.txt", "link"))
+
+	fi, lstatCalled, err := fs.LstatIfPossible("link")
+	require.NoError(t, err)
+	assert.True(t, lstatCalled)
+	assert.NotZero(t, fi.Mode()&os.ModeSymlink)
+
+	_, err = fs.ReadlinkIfPossible("link")
+	require.EqualError(t, err, "readlink error")
+
+	data, err := afero.ReadFile(fs, "link")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+}
@@ -0,0 +1,49 @@
+package aferomock
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// ErrNotImplemented indicates that a method is called but its callback is not set.
+var ErrNotImplemented = errors.New("not implemented")
+
+// UnsetFunc handles a call of a method of FsCallbacks, FileCallbacks or FileInfoCallbacks whose callback is not set and
+// that has no fallback. The method is qualified with the type, for example FsCallbacks.Chmod. The returned error is
+// the error of the call, the other results are zero values.
+//
+// When no UnsetFunc is set, the call panics with a message naming the missing callback.
+type UnsetFunc func(method string, args ...interface{}) error
+
+// FailUnset returns an UnsetFunc that fails the test with the method and its arguments, and returns ErrNotImplemented.
+//
+//	c := aferomock.FsCallbacks{
+//		StatFunc: func(string) (os.FileInfo, error) { ... },
+//		Unset:    aferomock.FailUnset(t),
+//	}
+func FailUnset(tb testing.TB) UnsetFunc {
+	return func(method string, args ...interface{}) error {
+		tb.Helper()
+		tb.Errorf("aferomock: %s is called but its callback is not set", callString(method, args...))
+
+		return fmt.Errorf("%s: %w", method, ErrNotImplemented)
+	}
+}
+
+// ReturnUnset returns an UnsetFunc that returns the given error, for example ErrNotImplemented.
+func ReturnUnset(err error) UnsetFunc {
+	return func(string, ...interface{}) error {
+		return err
+	}
+}
+
+func unset(fn UnsetFunc, typ, method string, args ...interface{}) error {
+	method = typ + "." + method
+
+	if fn == nil {
+		panic(fmt.Sprintf("aferomock: %s is called but %sFunc is not set", callString(method, args...), method))
+	}
+
+	return fn(method, args...)
+}
@@ -0,0 +1,148 @@
+package aferomock_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestFsCallbacks_Unset_Panic(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.FsCallbacks{}
+
+	assert.PanicsWithValue(t, `aferomock: FsCallbacks.Chmod("file.txt", -rw-r--r--) is called but FsCallbacks.ChmodFunc is not set`, func() {
+		_ = fs.Chmod("file.txt", 0o644) //nolint: errcheck
+	})
+}
+
+func TestFsCallbacks_Unset_Fail(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+
+	fs := aferomock.FsCallbacks{Unset: aferomock.FailUnset(ft)}
+
+	f, err := fs.Open("file.txt")
+
+	assert.Nil(t, f)
+	require.ErrorIs(t, err, aferomock.ErrNotImplemented)
+	require.EqualError(t, err, "FsCallbacks.Open: not implemented")
+
+	assert.Empty(t, fs.Name())
+
+	expected := []string{
+		`aferomock: FsCallbacks.Open("file.txt") is called but its callback is not set`,
+		`aferomock: FsCallbacks.Name() is called but its callback is not set`,
+	}
+
+	assert.Equal(t, expected, ft.Errors())
+}
+
+func TestFsCallbacks_Unset_Return(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.FsCallbacks{Unset: aferomock.ReturnUnset(aferomock.ErrNotImplemented)}
+
+	require.ErrorIs(t, fs.Remove("file.txt"), aferomock.ErrNotImplemented)
+
+	fi, err := fs.Stat("file.txt")
+
+	assert.Nil(t, fi)
+	require.ErrorIs(t, err, aferomock.ErrNotImplemented)
+}
+
+func TestFsCallbacks_Fallback(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.FsCallbacks{
+		RemoveFunc: func(string) error {
+			return errors.New("remove error")
+		},
+		Fallback: afero.NewMemMapFs(),
+		Unset:    aferomock.FailUnset(t),
+	}
+
+	require.NoError(t, fs.Mkdir("data", os.ModePerm))
+	require.EqualError(t, fs.Remove("data"), "remove error")
+
+	fi, err := fs.Stat("data")
+	require.NoError(t, err)
+	assert.True(t, fi.IsDir())
+}
+
+func TestFileCallbacks_Unset(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+
+	f := aferomock.FileCallbacks{
+		NameFunc: func() string {
+			return "file.txt"
+		},
+		Unset: aferomock.FailUnset(ft),
+	}
+
+	n, err := f.Write([]byte("hello"))
+
+	assert.Equal(t, "file.txt", f.Name())
+	assert.Equal(t, 0, n)
+	require.ErrorIs(t, err, aferomock.ErrNotImplemented)
+
+	assert.Equal(t, []string{`aferomock: FileCallbacks.Write("hello") is called but its callback is not set`}, ft.Errors())
+
+	assert.PanicsWithValue(t, `aferomock: FileCallbacks.Sync() is called but FileCallbacks.SyncFunc is not set`, func() {
+		_ = aferomock.FileCallbacks{}.Sync() //nolint: errcheck
+	})
+}
+
+func TestFileCallbacks_Fallback(t *testing.T) {
+	t.Parallel()
+
+	file, err := afero.NewMemMapFs().Create("file.txt")
+	require.NoError(t, err)
+
+	f := aferomock.FileCallbacks{Fallback: file}
+
+	n, err := f.WriteString("hello")
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, n)
+	assert.Equal(t, "file.txt", f.Name())
+}
+
+func TestFileInfoCallbacks_Unset(t *testing.T) {
+	t.Parallel()
+
+	ft := newFakeT(t)
+
+	fi := aferomock.FileInfoCallbacks{
+		Fallback: aferomock.MockFileInfo(func(fi *aferomock.FileInfo) {
+			fi.On("Name").Return("file.txt")
+		})(t),
+		SizeFunc: func() int64 {
+			return 42
+		},
+	}
+
+	assert.Equal(t, "file.txt", fi.Name())
+	assert.Equal(t, int64(42), fi.Size())
+
+	fi = aferomock.FileInfoCallbacks{Unset: aferomock.FailUnset(ft)}
+
+	assert.False(t, fi.IsDir())
+	assert.Nil(t, fi.Sys())
+
+	expected := []string{
+		`aferomock: FileInfoCallbacks.IsDir() is called but its callback is not set`,
+		`aferomock: FileInfoCallbacks.Sys() is called but its callback is not set`,
+	}
+
+	assert.Equal(t, expected, ft.Errors())
+}
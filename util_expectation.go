@@ -0,0 +1,150 @@
+package aferomock
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// tempFileRandomLen is the length of the random string of the name of a temp file created by afero.TempFile.
+const tempFileRandomLen = 9
+
+// ExpectReadFile expects the calls that afero.ReadFile makes to read the file: Open, Stat, Read until io.EOF and
+// Close.
+//
+//	fs := aferomock.NewFs(t)
+//
+//	aferomock.ExpectReadFile(t, fs, "config.yaml", []byte("key: value"))
+func ExpectReadFile(tb testing.TB, fs *Fs, path string, data []byte) {
+	fs.ExpectOpen(tb, path).WithFile(func(f *File) {
+		f.On("Stat").Return(WalkFile(filepath.Base(path), int64(len(data))).FileInfo(), nil).Once()
+	}, ReadScript(ReadStep{Data: data}, ReadErr(io.EOF)))
+}
+
+// ExpectWriteFile expects the calls that afero.WriteFile makes to write the file: OpenFile, Write and Close. The data
+// is a []byte, a string, or a matcher such as mock.Anything or mock.MatchedBy.
+//
+//	fs := aferomock.NewFs(t)
+//
+//	aferomock.ExpectWriteFile(t, fs, "config.yaml", "key: value", 0o644)
+func ExpectWriteFile(tb testing.TB, fs *Fs, path string, data interface{}, perm os.FileMode) {
+	if s, ok := data.(string); ok {
+		data = []byte(s)
+	}
+
+	fs.ExpectOpenFile(tb, path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm).WithFile(func(f *File) {
+		f.On("Write", data).Return(func(p []byte) (int, error) {
+			return len(p), nil
+		}).Once()
+	})
+}
+
+// ExpectExists expects the call that afero.Exists makes to check whether the path exists: Stat.
+func ExpectExists(fs *Fs, path string, exists bool) {
+	expectStat(fs, path, exists, WalkFile(filepath.Base(path), 0))
+}
+
+// ExpectDirExists expects the call that afero.DirExists makes to check whether the directory exists: Stat.
+func ExpectDirExists(fs *Fs, path string, exists bool) {
+	expectStat(fs, path, exists, WalkDir(filepath.Base(path)))
+}
+
+// ExpectIsEmpty expects the calls that afero.IsEmpty makes to check whether the file or the directory exists and is
+// empty: Stat twice, and Open, Readdir and Close for a directory. A file is empty when its Size is zero, a directory is
+// empty when it has no Entries. The name of the entry is ignored.
+//
+//	fs := aferomock.NewFs(t)
+//
+//	aferomock.ExpectIsEmpty(t, fs, "data", aferomock.WalkDir("", aferomock.WalkFile("file.txt", 10)))
+func ExpectIsEmpty(tb testing.TB, fs *Fs, path string, entry WalkEntry) {
+	entry.Name = filepath.Base(path)
+
+	fs.On("Stat", path).Return(entry.FileInfo(), nil).Twice()
+
+	if entry.Mode.IsDir() {
+		ExpectReadDir(tb, fs, path, entry)
+	}
+}
+
+// ExpectTempFile expects the call that afero.TempFile makes to create a temp file in the directory with the pattern:
+// OpenFile with a random name. The Name of the File mock returns the random name. Like afero.TempFile, an empty
+// directory is os.TempDir().
+//
+//	fs := aferomock.NewFs(t)
+//
+//	aferomock.ExpectTempFile(t, fs, "", "config-*.yaml").WithFile(func(f *aferomock.File) {
+//		f.On("WriteString", "key: value").Return(10, nil)
+//	})
+func ExpectTempFile(tb testing.TB, fs *Fs, dir, pattern string) *OpenExpectation {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	prefix, suffix := pattern, ""
+
+	if pos := strings.LastIndex(pattern, "*"); pos != -1 {
+		prefix, suffix = pattern[:pos], pattern[pos+1:]
+	}
+
+	var name atomic.Value
+
+	e := fs.ExpectOpenFile(tb, mock.MatchedBy(func(path string) bool {
+		return isTempFileName(path, dir, prefix, suffix)
+	}), os.O_RDWR|os.O_CREATE|os.O_EXCL, os.FileMode(0o600))
+
+	e.Run(func(args mock.Arguments) {
+		name.Store(args.String(0))
+	})
+
+	e.File().On("Name").Maybe().Return(func() string {
+		s, _ := name.Load().(string) //nolint: errcheck
+
+		return s
+	})
+
+	return e
+}
+
+func expectStat(fs *Fs, path string, exists bool, entry WalkEntry) {
+	if exists {
+		fs.On("Stat", path).Return(entry.FileInfo(), nil).Once()
+
+		return
+	}
+
+	fs.On("Stat", path).Return(nil, &os.PathError{Op: "stat", Path: path, Err: os.ErrNotExist}).Once()
+}
+
+func isTempFileName(path, dir, prefix, suffix string) bool {
+	// The prefix is joined with a placeholder, so that the separator is kept when the prefix is empty.
+	start := strings.TrimSuffix(filepath.Join(dir, prefix+"_"), "_")
+
+	if !strings.HasPrefix(path, start) {
+		return false
+	}
+
+	name := strings.TrimPrefix(path, start)
+
+	if !strings.HasSuffix(name, suffix) {
+		return false
+	}
+
+	random := strings.TrimSuffix(name, suffix)
+
+	if len(random) != tempFileRandomLen {
+		return false
+	}
+
+	for _, c := range random {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,245 @@
+package aferomock_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func TestExpectReadFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		data     string
+	}{
+		{
+			scenario: "empty",
+		},
+		{
+			scenario: "small",
+			data:     "key: value",
+		},
+		{
+			scenario: "large",
+			data:     strings.Repeat("0123456789", 1000),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+				aferomock.ExpectReadFile(t, fs, "config.yaml", []byte(tc.data))
+			})(t)
+
+			data, err := afero.ReadFile(fs, "config.yaml")
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.data, string(data))
+		})
+	}
+}
+
+func TestExpectWriteFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		data     interface{}
+	}{
+		{
+			scenario: "string",
+			data:     "key: value",
+		},
+		{
+			scenario: "bytes",
+			data:     []byte("key: value"),
+		},
+		{
+			scenario: "matcher",
+			data: mock.MatchedBy(func(p []byte) bool {
+				return strings.HasPrefix(string(p), "key:")
+			}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+				aferomock.ExpectWriteFile(t, fs, "config.yaml", tc.data, 0o644)
+			})(t)
+
+			err := afero.WriteFile(fs, "config.yaml", []byte("key: value"), 0o644)
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestExpectExists(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		aferomock.ExpectExists(fs, "config.yaml", true)
+		aferomock.ExpectExists(fs, "unknown.yaml", false)
+		aferomock.ExpectDirExists(fs, "data", true)
+		aferomock.ExpectDirExists(fs, "unknown", false)
+	})(t)
+
+	exists, err := afero.Exists(fs, "config.yaml")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = afero.Exists(fs, "unknown.yaml")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = afero.DirExists(fs, "data")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = afero.DirExists(fs, "unknown")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestExpectIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	unreadable := aferomock.WalkDir("")
+	unreadable.ReadErr = errors.New("read error")
+
+	testCases := []struct {
+		scenario       string
+		entry          aferomock.WalkEntry
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario:       "empty file",
+			entry:          aferomock.WalkFile("", 0),
+			expectedResult: true,
+		},
+		{
+			scenario: "file",
+			entry:    aferomock.WalkFile("", 10),
+		},
+		{
+			scenario:       "empty dir",
+			entry:          aferomock.WalkDir(""),
+			expectedResult: true,
+		},
+		{
+			scenario: "dir",
+			entry:    aferomock.WalkDir("", aferomock.WalkFile("file.txt", 0)),
+		},
+		{
+			scenario:      "unreadable dir",
+			entry:         unreadable,
+			expectedError: "read error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+				aferomock.ExpectIsEmpty(t, fs, "data", tc.entry)
+			})(t)
+
+			empty, err := afero.IsEmpty(fs, "data")
+
+			assert.Equal(t, tc.expectedResult, empty)
+
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestExpectTempFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario    string
+		dir         string
+		pattern     string
+		expectedDir string
+	}{
+		{
+			scenario:    "pattern with star",
+			dir:         "tmp",
+			pattern:     "config-*.yaml",
+			expectedDir: "tmp",
+		},
+		{
+			scenario:    "pattern without star",
+			dir:         "tmp",
+			pattern:     "config-",
+			expectedDir: "tmp",
+		},
+		{
+			scenario:    "empty pattern",
+			dir:         "tmp",
+			expectedDir: "tmp",
+		},
+		{
+			scenario:    "default dir",
+			pattern:     "config-*",
+			expectedDir: os.TempDir(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+				aferomock.ExpectTempFile(t, fs, tc.dir, tc.pattern).WithFile(func(f *aferomock.File) {
+					f.On("WriteString", "key: value").Return(10, nil)
+				})
+			})(t)
+
+			f, err := afero.TempFile(fs, tc.dir, tc.pattern)
+			require.NoError(t, err)
+
+			_, err = f.WriteString("key: value")
+			require.NoError(t, err)
+
+			require.NoError(t, f.Close())
+
+			assert.Equal(t, tc.expectedDir, filepath.Dir(f.Name()))
+		})
+	}
+}
+
+func TestExpectTempFile_WrongDir(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		aferomock.ExpectTempFile(t, fs, "tmp", "config-*").WithoutClose().Maybe()
+
+		fs.On("OpenFile", mock.Anything, mock.Anything, mock.Anything).Return(nil, os.ErrPermission)
+	})(t)
+
+	_, err := afero.TempFile(fs, "other", "config-*")
+
+	require.ErrorIs(t, err, os.ErrPermission)
+}
@@ -0,0 +1,146 @@
+package aferomock
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// WalkEntry is an entry of a tree for ExpectWalk and ExpectReadDir.
+type WalkEntry struct {
+	Name    string
+	Mode    os.FileMode
+	Size    int64
+	ModTime time.Time
+
+	// Entries are the entries of a directory.
+	Entries []WalkEntry
+
+	// StatErr is the error of Stat on the entry.
+	StatErr error
+	// OpenErr is the error of Open on the directory.
+	OpenErr error
+	// ReadErr is the error of Readdirnames or Readdir on the directory.
+	ReadErr error
+	// Skip indicates that the walk function returns filepath.SkipDir for the directory, so it is not read.
+	Skip bool
+}
+
+// WalkDir returns a WalkEntry of a directory with the given entries.
+func WalkDir(name string, entries ...WalkEntry) WalkEntry {
+	return WalkEntry{Name: name, Mode: os.ModeDir | 0o755, Entries: entries}
+}
+
+// WalkFile returns a WalkEntry of a regular file with the given size.
+func WalkFile(name string, size int64) WalkEntry {
+	return WalkEntry{Name: name, Mode: 0o644, Size: size}
+}
+
+// FileInfo returns a fs.FileInfo of the entry.
+func (e WalkEntry) FileInfo() os.FileInfo {
+	return FileInfoCallbacks{
+		NameFunc:    func() string { return e.Name },
+		SizeFunc:    func() int64 { return e.Size },
+		ModeFunc:    func() os.FileMode { return e.Mode },
+		ModTimeFunc: func() time.Time { return e.ModTime },
+		IsDirFunc:   func() bool { return e.Mode.IsDir() },
+		SysFunc:     func() interface{} { return nil },
+	}
+}
+
+// ExpectWalk expects the calls that afero.Walk makes to walk the tree at root: Stat of every entry, and Open,
+// Readdirnames and Close of every directory, in the lexical order of afero.Walk. The name of the tree is ignored, the
+// root is used instead.
+//
+// The walk is expected to visit the whole tree, a directory that is skipped by the walk function has to be marked with
+// Skip. An entry with StatErr, OpenErr or ReadErr is not descended into.
+//
+//	fs := aferomock.NewFs(t)
+//
+//	aferomock.ExpectWalk(t, fs, "data", aferomock.WalkDir("",
+//		aferomock.WalkFile("b.txt", 10),
+//		aferomock.WalkDir("a", aferomock.WalkFile("c.txt", 20)),
+//	))
+func ExpectWalk(tb testing.TB, fs *Fs, root string, tree WalkEntry) {
+	tree.Name = filepath.Base(root)
+
+	expectWalk(tb, fs, root, tree)
+}
+
+func expectWalk(tb testing.TB, fs *Fs, path string, e WalkEntry) {
+	if e.StatErr != nil {
+		fs.On("Stat", path).Return(nil, e.StatErr).Once()
+
+		return
+	}
+
+	fs.On("Stat", path).Return(e.FileInfo(), nil).Once()
+
+	if !e.Mode.IsDir() || e.Skip {
+		return
+	}
+
+	if e.OpenErr != nil {
+		fs.On("Open", path).Return(nil, e.OpenErr).Once()
+
+		return
+	}
+
+	names := make([]string, len(e.Entries))
+
+	for i, c := range e.Entries {
+		names[i] = c.Name
+	}
+
+	fs.ExpectOpen(tb, path).WithFile(func(f *File) {
+		if e.ReadErr != nil {
+			f.On("Readdirnames", -1).Return(nil, e.ReadErr).Once()
+		} else {
+			f.On("Readdirnames", -1).Return(names, nil).Once()
+		}
+	})
+
+	if e.ReadErr != nil {
+		return
+	}
+
+	for _, c := range sortedEntries(e.Entries) {
+		expectWalk(tb, fs, filepath.Join(path, c.Name), c)
+	}
+}
+
+// ExpectReadDir expects the calls that afero.ReadDir makes to read the directory: Open, Readdir and Close. The OpenErr
+// and ReadErr of the directory are returned by Open and Readdir.
+func ExpectReadDir(tb testing.TB, fs *Fs, dirname string, dir WalkEntry) {
+	if dir.OpenErr != nil {
+		fs.On("Open", dirname).Return(nil, dir.OpenErr).Once()
+
+		return
+	}
+
+	infos := make([]os.FileInfo, len(dir.Entries))
+
+	for i, c := range dir.Entries {
+		infos[i] = c.FileInfo()
+	}
+
+	fs.ExpectOpen(tb, dirname).WithFile(func(f *File) {
+		if dir.ReadErr != nil {
+			f.On("Readdir", -1).Return(nil, dir.ReadErr).Once()
+		} else {
+			f.On("Readdir", -1).Return(infos, nil).Once()
+		}
+	})
+}
+
+func sortedEntries(entries []WalkEntry) []WalkEntry {
+	sorted := append([]WalkEntry(nil), entries...)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted
+}
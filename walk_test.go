@@ -0,0 +1,173 @@
+package aferomock_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.nhat.io/aferomock"
+)
+
+func walkPaths(fs afero.Fs, root string, skip string) ([]string, error) {
+	var paths []string
+
+	err := afero.Walk(fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			paths = append(paths, path+": "+err.Error())
+
+			return nil
+		}
+
+		paths = append(paths, path)
+
+		if path == skip {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+
+	return paths, err
+}
+
+func TestExpectWalk(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		aferomock.ExpectWalk(t, fs, "data", aferomock.WalkDir("",
+			aferomock.WalkFile("c.txt", 10),
+			aferomock.WalkDir("b",
+				aferomock.WalkFile("e.txt", 20),
+				aferomock.WalkFile("d.txt", 30),
+			),
+			aferomock.WalkFile("a.txt", 40),
+		))
+	})(t)
+
+	paths, err := walkPaths(fs, "data", "")
+	require.NoError(t, err)
+
+	expected := []string{"data", "data/a.txt", "data/b", "data/b/d.txt", "data/b/e.txt", "data/c.txt"}
+
+	assert.Equal(t, expected, paths)
+}
+
+func TestExpectWalk_SameAsMemMapFs(t *testing.T) {
+	t.Parallel()
+
+	memFs := afero.NewMemMapFs()
+
+	require.NoError(t, memFs.MkdirAll("data/b/c", os.ModePerm))
+	require.NoError(t, afero.WriteFile(memFs, "data/b/c/d.txt", []byte("d"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "data/a.txt", []byte("a"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "data/b/z.txt", []byte("z"), 0o644))
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		aferomock.ExpectWalk(t, fs, "data", aferomock.WalkDir("",
+			aferomock.WalkDir("b",
+				aferomock.WalkFile("z.txt", 1),
+				aferomock.WalkDir("c", aferomock.WalkFile("d.txt", 1)),
+			),
+			aferomock.WalkFile("a.txt", 1),
+		))
+	})(t)
+
+	expected, err := walkPaths(memFs, "data", "")
+	require.NoError(t, err)
+
+	actual, err := walkPaths(fs, "data", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestExpectWalk_Errors(t *testing.T) {
+	t.Parallel()
+
+	unreadable := aferomock.WalkDir("unreadable", aferomock.WalkFile("x.txt", 1))
+	unreadable.ReadErr = errors.New("read error")
+
+	unopenable := aferomock.WalkDir("unopenable")
+	unopenable.OpenErr = errors.New("open error")
+
+	broken := aferomock.WalkFile("broken.txt", 1)
+	broken.StatErr = errors.New("stat error")
+
+	skipped := aferomock.WalkDir("skipped", aferomock.WalkFile("y.txt", 1))
+	skipped.Skip = true
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		aferomock.ExpectWalk(t, fs, "data", aferomock.WalkDir("", unreadable, unopenable, broken, skipped))
+	})(t)
+
+	paths, err := walkPaths(fs, "data", "data/skipped")
+	require.NoError(t, err)
+
+	expected := []string{
+		"data",
+		"data/broken.txt: stat error",
+		"data/skipped",
+		"data/unopenable",
+		"data/unopenable: open error",
+		"data/unreadable",
+		"data/unreadable: read error",
+	}
+
+	assert.Equal(t, expected, paths)
+}
+
+func TestExpectWalk_RootStatError(t *testing.T) {
+	t.Parallel()
+
+	root := aferomock.WalkDir("")
+	root.StatErr = os.ErrNotExist
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		aferomock.ExpectWalk(t, fs, "data", root)
+	})(t)
+
+	paths, err := walkPaths(fs, "data", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"data: file does not exist"}, paths)
+}
+
+func TestExpectReadDir(t *testing.T) {
+	t.Parallel()
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		aferomock.ExpectReadDir(t, fs, "data", aferomock.WalkDir("",
+			aferomock.WalkFile("b.txt", 10),
+			aferomock.WalkDir("a"),
+		))
+	})(t)
+
+	infos, err := afero.ReadDir(fs, "data")
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+
+	assert.Equal(t, "a", infos[0].Name())
+	assert.True(t, infos[0].IsDir())
+	assert.Equal(t, "b.txt", infos[1].Name())
+	assert.Equal(t, int64(10), infos[1].Size())
+}
+
+func TestExpectReadDir_Error(t *testing.T) {
+	t.Parallel()
+
+	dir := aferomock.WalkDir("")
+	dir.ReadErr = errors.New("read error")
+
+	fs := aferomock.MockFs(func(fs *aferomock.Fs) {
+		aferomock.ExpectReadDir(t, fs, "data", dir)
+	})(t)
+
+	_, err := afero.ReadDir(fs, "data")
+
+	require.EqualError(t, err, "read error")
+}